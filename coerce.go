@@ -0,0 +1,147 @@
+package persistence
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+var ErrLossy = errors.New("lossy conversion")
+var ErrNotNumeric = errors.New("value is not numeric")
+
+const (
+	twoTo63 = 9223372036854775808.0
+	twoTo64 = 18446744073709551616.0
+)
+
+func parseNumber(s string) (any, error) {
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i, nil
+	}
+
+	if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return u, nil
+	}
+
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f, nil
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrNotNumeric, s)
+}
+
+func ToInt(v any) (int64, error) {
+	switch n := v.(type) {
+	case int64:
+		return n, nil
+	case uint64:
+		if n > math.MaxInt64 {
+			return int64(n), fmt.Errorf("%w: %d to int", ErrLossy, n)
+		}
+
+		return int64(n), nil
+	case float64:
+		if n != math.Trunc(n) || n < -twoTo63 || n >= twoTo63 {
+			return int64(n), fmt.Errorf("%w: %g to int", ErrLossy, n)
+		}
+
+		return int64(n), nil
+	case string:
+		p, err := parseNumber(n)
+		if err != nil {
+			return 0, err
+		}
+
+		return ToInt(p)
+	}
+
+	return 0, fmt.Errorf("%w: %T", ErrNotNumeric, v)
+}
+
+func ToUInt(v any) (uint64, error) {
+	switch n := v.(type) {
+	case uint64:
+		return n, nil
+	case int64:
+		if n < 0 {
+			return uint64(n), fmt.Errorf("%w: %d to uint", ErrLossy, n)
+		}
+
+		return uint64(n), nil
+	case float64:
+		if n != math.Trunc(n) || n < 0 || n >= twoTo64 {
+			return uint64(n), fmt.Errorf("%w: %g to uint", ErrLossy, n)
+		}
+
+		return uint64(n), nil
+	case string:
+		p, err := parseNumber(n)
+		if err != nil {
+			return 0, err
+		}
+
+		return ToUInt(p)
+	}
+
+	return 0, fmt.Errorf("%w: %T", ErrNotNumeric, v)
+}
+
+func ToFloat(v any) (float64, error) {
+	switch n := v.(type) {
+	case float64:
+		return n, nil
+	case int64:
+		if f := float64(n); f >= twoTo63 || int64(f) != n {
+			return f, fmt.Errorf("%w: %d to float", ErrLossy, n)
+		}
+
+		return float64(n), nil
+	case uint64:
+		if f := float64(n); f >= twoTo64 || uint64(f) != n {
+			return f, fmt.Errorf("%w: %d to float", ErrLossy, n)
+		}
+
+		return float64(n), nil
+	case string:
+		p, err := parseNumber(n)
+		if err != nil {
+			return 0, err
+		}
+
+		return ToFloat(p)
+	}
+
+	return 0, fmt.Errorf("%w: %T", ErrNotNumeric, v)
+}
+
+func coerce[T any](s Section, key string, to func(any) (T, error), defValue []T) (T, bool, error) {
+	def := *new(T)
+	if len(defValue) > 0 {
+		def = defValue[0]
+	}
+
+	v, _, found := Get(s, key)
+	if !found {
+		return def, false, nil
+	}
+
+	c, err := to(v)
+	if err != nil {
+		return def, false, fmt.Errorf("coerce %q: %w", key, err)
+	}
+
+	return c, true, nil
+}
+
+func CoerceInt(s Section, key string, defValue ...int64) (int64, bool, error) {
+	return coerce(s, key, ToInt, defValue)
+}
+
+func CoerceUInt(s Section, key string, defValue ...uint64) (uint64, bool, error) {
+	return coerce(s, key, ToUInt, defValue)
+}
+
+func CoerceFloat(s Section, key string, defValue ...float64) (float64, bool, error) {
+	return coerce(s, key, ToFloat, defValue)
+}
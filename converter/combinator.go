@@ -0,0 +1,209 @@
+package converter
+
+import (
+	"fmt"
+	"github.com/shimmeringbee/persistence"
+	"math"
+	"slices"
+	"strconv"
+)
+
+type Encoder[T any] func(persistence.Section, string, T)
+type Decoder[T any] func(persistence.Section, string) (T, bool)
+
+func SliceEncoder[T any](elemEnc Encoder[T]) Encoder[[]T] {
+	return func(s persistence.Section, k string, v []T) {
+		s.SectionDelete(k)
+		ss := s.Section(k)
+		ss.Set("count", int64(len(v)))
+
+		values := ss.Section("values")
+
+		for i, e := range v {
+			elemEnc(values, strconv.Itoa(i), e)
+		}
+	}
+}
+
+func SliceDecoder[T any](elemDec Decoder[T]) Decoder[[]T] {
+	return func(s persistence.Section, k string) ([]T, bool) {
+		ss, found := s.SectionIfExists(k)
+		if !found {
+			return nil, false
+		}
+
+		count, cFound := ss.Int("count")
+		values, vFound := ss.SectionIfExists("values")
+
+		if !cFound || !vFound || count < 0 {
+			return nil, false
+		}
+
+		var v []T
+
+		for i := 0; i < int(count); i++ {
+			e, found := elemDec(values, strconv.Itoa(i))
+			if !found {
+				return nil, false
+			}
+
+			v = append(v, e)
+		}
+
+		return v, true
+	}
+}
+
+func SliceStrictDecoder[T any](elemDec StrictDecoder[T]) StrictDecoder[[]T] {
+	return func(s persistence.Section, k string) ([]T, error) {
+		ss, found := s.SectionIfExists(k)
+		if !found {
+			if s.Exists(k) {
+				return nil, fmt.Errorf("%q: expected section, found type %d: %w", k, s.Type(k), ErrWrongType)
+			}
+
+			return nil, fmt.Errorf("%q: %w", k, ErrMissing)
+		}
+
+		count, err := strictInt[int](ss, "count", 0, math.MaxInt32)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", k, err)
+		}
+
+		values, found := ss.SectionIfExists("values")
+		if !found {
+			return nil, fmt.Errorf("%q: %q: %w", k, "values", ErrMissing)
+		}
+
+		var v []T
+
+		for i := 0; i < count; i++ {
+			e, err := elemDec(values, strconv.Itoa(i))
+			if err != nil {
+				return nil, fmt.Errorf("%q: %w", k, err)
+			}
+
+			v = append(v, e)
+		}
+
+		return v, nil
+	}
+}
+
+func MapEncoder[K comparable, V any](keyFmt func(K) string, valEnc Encoder[V]) Encoder[map[K]V] {
+	return func(s persistence.Section, k string, v map[K]V) {
+		s.SectionDelete(k)
+		ss := s.Section(k)
+
+		for mk, mv := range v {
+			valEnc(ss, keyFmt(mk), mv)
+		}
+	}
+}
+
+func MapDecoder[K comparable, V any](keyParse func(string) (K, bool), valDec Decoder[V]) Decoder[map[K]V] {
+	return func(s persistence.Section, k string) (map[K]V, bool) {
+		ss, found := s.SectionIfExists(k)
+		if !found {
+			return nil, false
+		}
+
+		keys := slices.Compact(slices.Sorted(slices.Values(append(ss.Keys(), ss.SectionKeys()...))))
+		v := make(map[K]V, len(keys))
+
+		for _, sk := range keys {
+			mk, ok := keyParse(sk)
+			if !ok {
+				continue
+			}
+
+			if mv, found := valDec(ss, sk); found {
+				v[mk] = mv
+			}
+		}
+
+		return v, true
+	}
+}
+
+func PointerEncoder[T any](enc Encoder[T]) Encoder[*T] {
+	return func(s persistence.Section, k string, v *T) {
+		if v == nil {
+			s.Delete(k)
+			s.SectionDelete(k)
+		} else {
+			enc(s, k, *v)
+		}
+	}
+}
+
+func PointerDecoder[T any](dec Decoder[T]) Decoder[*T] {
+	return func(s persistence.Section, k string) (*T, bool) {
+		if v, found := dec(s, k); found {
+			return &v, true
+		} else {
+			return nil, false
+		}
+	}
+}
+
+type Optional[T any] struct {
+	Value T
+	Valid bool
+}
+
+func OptionalEncoder[T any](enc Encoder[T]) Encoder[Optional[T]] {
+	return func(s persistence.Section, k string, v Optional[T]) {
+		if v.Valid {
+			enc(s, k, v.Value)
+		} else {
+			s.Delete(k)
+			s.SectionDelete(k)
+		}
+	}
+}
+
+func OptionalDecoder[T any](dec Decoder[T]) Decoder[Optional[T]] {
+	return func(s persistence.Section, k string) (Optional[T], bool) {
+		v, found := dec(s, k)
+		return Optional[T]{Value: v, Valid: found}, true
+	}
+}
+
+type integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64
+}
+
+func IntKeyFormat[K integer](k K) string {
+	if k < 0 {
+		return strconv.FormatInt(int64(k), 10)
+	}
+
+	return strconv.FormatUint(uint64(k), 10)
+}
+
+func IntKeyParse[K integer](s string) (K, bool) {
+	if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if k := K(v); int64(k) == v && (k < 0) == (v < 0) {
+			return k, true
+		}
+
+		return 0, false
+	}
+
+	if v, err := strconv.ParseUint(s, 10, 64); err == nil {
+		if k := K(v); uint64(k) == v && k >= 0 {
+			return k, true
+		}
+	}
+
+	return 0, false
+}
+
+func StringKeyFormat[K ~string](k K) string {
+	return string(k)
+}
+
+func StringKeyParse[K ~string](s string) (K, bool) {
+	return K(s), true
+}
@@ -0,0 +1,260 @@
+package converter
+
+import (
+	"github.com/shimmeringbee/persistence/impl/memory"
+	"github.com/shimmeringbee/zigbee"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestSlice(t *testing.T) {
+	t.Run("stored and retrieved in order", func(t *testing.T) {
+		s := memory.New()
+
+		expected := []zigbee.Endpoint{3, 1, 2, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+
+		Store(s, Key, expected, SliceEncoder(EndpointEncoder))
+
+		actual, found := Retrieve(s, Key, SliceDecoder(EndpointDecoder))
+		assert.True(t, found)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("stored and retrieved with section based elements", func(t *testing.T) {
+		s := memory.New()
+
+		expected := []zigbee.NodeDescription{{LogicalType: zigbee.Router}, {LogicalType: zigbee.EndDevice, ManufacturerCode: 0x1234}}
+
+		Store(s, Key, expected, SliceEncoder(NodeDescriptionEncoder))
+
+		actual, found := Retrieve(s, Key, SliceDecoder(NodeDescriptionDecoder))
+		assert.True(t, found)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("nested slices are stored and retrieved", func(t *testing.T) {
+		s := memory.New()
+
+		expected := [][]zigbee.ClusterID{{1, 2}, {3}}
+
+		Store(s, Key, expected, SliceEncoder(SliceEncoder(ClusterIDEncoder)))
+
+		actual, found := Retrieve(s, Key, SliceDecoder(SliceDecoder(ClusterIDDecoder)))
+		assert.True(t, found)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("missing slice is not found", func(t *testing.T) {
+		s := memory.New()
+
+		_, found := Retrieve(s, Key, SliceDecoder(EndpointDecoder))
+		assert.False(t, found)
+	})
+
+	t.Run("empty slice is found", func(t *testing.T) {
+		s := memory.New()
+
+		Store(s, Key, []zigbee.Endpoint{}, SliceEncoder(EndpointEncoder))
+
+		actual, found := Retrieve(s, Key, SliceDecoder(EndpointDecoder))
+		assert.True(t, found)
+		assert.Empty(t, actual)
+	})
+
+	t.Run("slice with a missing element is not found", func(t *testing.T) {
+		s := memory.New()
+
+		Store(s, Key, []zigbee.Endpoint{1, 2, 3}, SliceEncoder(EndpointEncoder))
+		s.Section(Key, "values").Delete("1")
+
+		_, found := Retrieve(s, Key, SliceDecoder(EndpointDecoder))
+		assert.False(t, found)
+	})
+
+	t.Run("slice with a malformed element is not found", func(t *testing.T) {
+		s := memory.New()
+
+		Store(s, Key, []zigbee.Endpoint{1, 2, 3}, SliceEncoder(EndpointEncoder))
+		s.Section(Key, "values").Set("2", "three")
+
+		_, found := Retrieve(s, Key, SliceDecoder(EndpointDecoder))
+		assert.False(t, found)
+	})
+
+	t.Run("slice without a count is not found", func(t *testing.T) {
+		s := memory.New()
+
+		Store(s, Key, []zigbee.Endpoint{1}, SliceEncoder(EndpointEncoder))
+		s.Section(Key).Delete("count")
+
+		_, found := Retrieve(s, Key, SliceDecoder(EndpointDecoder))
+		assert.False(t, found)
+	})
+}
+
+func TestSliceStrict(t *testing.T) {
+	t.Run("stored and retrieved in order", func(t *testing.T) {
+		s := memory.New()
+
+		expected := []zigbee.Endpoint{3, 1, 2}
+
+		Store(s, Key, expected, SliceEncoder(EndpointEncoder))
+
+		actual, err := RetrieveStrict(s, Key, SliceStrictDecoder(EndpointStrictDecoder))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("missing slice is reported", func(t *testing.T) {
+		_, err := RetrieveStrict(memory.New(), Key, SliceStrictDecoder(EndpointStrictDecoder))
+		assert.ErrorIs(t, err, ErrMissing)
+	})
+
+	t.Run("value in place of a slice is reported", func(t *testing.T) {
+		s := memory.New()
+		s.Set(Key, int64(1))
+
+		_, err := RetrieveStrict(s, Key, SliceStrictDecoder(EndpointStrictDecoder))
+		assert.ErrorIs(t, err, ErrWrongType)
+	})
+
+	t.Run("missing element is reported", func(t *testing.T) {
+		s := memory.New()
+
+		Store(s, Key, []zigbee.Endpoint{1, 2, 3}, SliceEncoder(EndpointEncoder))
+		s.Section(Key, "values").Delete("1")
+
+		_, err := RetrieveStrict(s, Key, SliceStrictDecoder(EndpointStrictDecoder))
+		assert.ErrorIs(t, err, ErrMissing)
+	})
+
+	t.Run("out of range element is reported", func(t *testing.T) {
+		s := memory.New()
+
+		Store(s, Key, []zigbee.Endpoint{1, 2, 3}, SliceEncoder(EndpointEncoder))
+		s.Section(Key, "values").Set("2", int64(300))
+
+		_, err := RetrieveStrict(s, Key, SliceStrictDecoder(EndpointStrictDecoder))
+		assert.ErrorIs(t, err, ErrOutOfRange)
+	})
+}
+
+func TestMap(t *testing.T) {
+	t.Run("stored and retrieved", func(t *testing.T) {
+		s := memory.New()
+
+		expected := map[zigbee.Endpoint]zigbee.EndpointDescription{
+			1: {Endpoint: 1, ProfileID: zigbee.ProfileHomeAutomation, InClusterList: []zigbee.ClusterID{0x0000, 0x0006}},
+			2: {Endpoint: 2, ProfileID: zigbee.ProfileHomeAutomation, OutClusterList: []zigbee.ClusterID{0x0019}},
+		}
+
+		Store(s, Key, expected, MapEncoder(IntKeyFormat[zigbee.Endpoint], EndpointDescriptionEncoder))
+
+		actual, found := Retrieve(s, Key, MapDecoder(IntKeyParse[zigbee.Endpoint], EndpointDescriptionDecoder))
+		assert.True(t, found)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("stored and retrieved with scalar values", func(t *testing.T) {
+		s := memory.New()
+
+		type name string
+
+		expected := map[name]zigbee.ClusterID{"basic": 0x0000, "onOff": 0x0006}
+
+		Store(s, Key, expected, MapEncoder(StringKeyFormat[name], ClusterIDEncoder))
+
+		actual, found := Retrieve(s, Key, MapDecoder(StringKeyParse[name], ClusterIDDecoder))
+		assert.True(t, found)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("overwriting a map removes stale entries", func(t *testing.T) {
+		s := memory.New()
+
+		Store(s, Key, map[int]zigbee.Endpoint{1: 1, 2: 2}, MapEncoder(IntKeyFormat[int], EndpointEncoder))
+		Store(s, Key, map[int]zigbee.Endpoint{3: 3}, MapEncoder(IntKeyFormat[int], EndpointEncoder))
+
+		actual, found := Retrieve(s, Key, MapDecoder(IntKeyParse[int], EndpointDecoder))
+		assert.True(t, found)
+		assert.Equal(t, map[int]zigbee.Endpoint{3: 3}, actual)
+	})
+}
+
+func TestIntKey(t *testing.T) {
+	t.Run("keys round trip across the full range", func(t *testing.T) {
+		for _, v := range []int64{math.MinInt64, -1, 0, 1, math.MaxInt64} {
+			k, ok := IntKeyParse[int64](IntKeyFormat(v))
+			assert.True(t, ok)
+			assert.Equal(t, v, k)
+		}
+
+		k, ok := IntKeyParse[uint64](IntKeyFormat(uint64(math.MaxUint64)))
+		assert.True(t, ok)
+		assert.Equal(t, uint64(math.MaxUint64), k)
+	})
+
+	t.Run("keys out of range for the key type are rejected", func(t *testing.T) {
+		_, ok := IntKeyParse[uint8]("256")
+		assert.False(t, ok)
+
+		_, ok = IntKeyParse[uint16]("-1")
+		assert.False(t, ok)
+
+		_, ok = IntKeyParse[int]("abc")
+		assert.False(t, ok)
+	})
+}
+
+func TestPointer(t *testing.T) {
+	t.Run("stored and retrieved", func(t *testing.T) {
+		s := memory.New()
+
+		expected := zigbee.Endpoint(1)
+
+		Store(s, Key, &expected, PointerEncoder(EndpointEncoder))
+
+		actual, found := Retrieve(s, Key, PointerDecoder(EndpointDecoder))
+		assert.True(t, found)
+		assert.Equal(t, &expected, actual)
+	})
+
+	t.Run("storing nil removes the value", func(t *testing.T) {
+		s := memory.New()
+
+		expected := zigbee.Endpoint(1)
+
+		Store(s, Key, &expected, PointerEncoder(EndpointEncoder))
+		Store(s, Key, nil, PointerEncoder(EndpointEncoder))
+
+		actual, found := Retrieve(s, Key, PointerDecoder(EndpointDecoder))
+		assert.False(t, found)
+		assert.Nil(t, actual)
+	})
+}
+
+func TestOptional(t *testing.T) {
+	t.Run("stored and retrieved", func(t *testing.T) {
+		s := memory.New()
+
+		expected := Optional[zigbee.Endpoint]{Value: 1, Valid: true}
+
+		Store(s, Key, expected, OptionalEncoder(EndpointEncoder))
+
+		actual, found := Retrieve(s, Key, OptionalDecoder(EndpointDecoder))
+		assert.True(t, found)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("absent value is retrieved as invalid", func(t *testing.T) {
+		s := memory.New()
+
+		Store(s, Key, Optional[zigbee.Endpoint]{Value: 1, Valid: true}, OptionalEncoder(EndpointEncoder))
+		Store(s, Key, Optional[zigbee.Endpoint]{}, OptionalEncoder(EndpointEncoder))
+
+		actual, found := Retrieve(s, Key, OptionalDecoder(EndpointDecoder))
+		assert.True(t, found)
+		assert.Equal(t, Optional[zigbee.Endpoint]{}, actual)
+	})
+}
@@ -0,0 +1,78 @@
+package converter
+
+import (
+	"github.com/shimmeringbee/persistence"
+	"github.com/shimmeringbee/zigbee"
+)
+
+func NodeDescriptionEncoder(s persistence.Section, k string, v zigbee.NodeDescription) {
+	s.SectionDelete(k)
+	ss := s.Section(k)
+
+	LogicalTypeEncoder(ss, "logicalType", v.LogicalType)
+	ManufacturerCodeEncoder(ss, "manufacturerCode", v.ManufacturerCode)
+}
+
+func NodeDescriptionDecoder(s persistence.Section, k string) (zigbee.NodeDescription, bool) {
+	ss, found := s.SectionIfExists(k)
+	if !found {
+		return zigbee.NodeDescription{}, false
+	}
+
+	logicalType, ltFound := LogicalTypeDecoder(ss, "logicalType")
+	manufacturerCode, mcFound := ManufacturerCodeDecoder(ss, "manufacturerCode")
+
+	if !ltFound || !mcFound {
+		return zigbee.NodeDescription{}, false
+	}
+
+	return zigbee.NodeDescription{LogicalType: logicalType, ManufacturerCode: manufacturerCode}, true
+}
+
+func EndpointDescriptionEncoder(s persistence.Section, k string, v zigbee.EndpointDescription) {
+	s.SectionDelete(k)
+	ss := s.Section(k)
+
+	EndpointEncoder(ss, "endpoint", v.Endpoint)
+	ProfileIDEncoder(ss, "profileId", v.ProfileID)
+	ss.Set("deviceId", int64(v.DeviceID))
+	ss.Set("deviceVersion", int64(v.DeviceVersion))
+	ClusterIDListEncoder(ss, "inClusterList", v.InClusterList)
+	ClusterIDListEncoder(ss, "outClusterList", v.OutClusterList)
+}
+
+func EndpointDescriptionDecoder(s persistence.Section, k string) (zigbee.EndpointDescription, bool) {
+	ss, found := s.SectionIfExists(k)
+	if !found {
+		return zigbee.EndpointDescription{}, false
+	}
+
+	endpoint, epFound := EndpointDecoder(ss, "endpoint")
+	profileID, pFound := ProfileIDDecoder(ss, "profileId")
+	deviceID, dFound := ss.Int("deviceId")
+	deviceVersion, dvFound := ss.Int("deviceVersion")
+
+	if !epFound || !pFound || !dFound || !dvFound {
+		return zigbee.EndpointDescription{}, false
+	}
+
+	inClusters, _ := ClusterIDListDecoder(ss, "inClusterList")
+	outClusters, _ := ClusterIDListDecoder(ss, "outClusterList")
+
+	return zigbee.EndpointDescription{
+		Endpoint:       endpoint,
+		ProfileID:      profileID,
+		DeviceID:       uint16(deviceID),
+		DeviceVersion:  uint8(deviceVersion),
+		InClusterList:  inClusters,
+		OutClusterList: outClusters,
+	}, true
+}
+
+func ClusterIDListEncoder(s persistence.Section, k string, v []zigbee.ClusterID) {
+	SliceEncoder(ClusterIDEncoder)(s, k, v)
+}
+
+func ClusterIDListDecoder(s persistence.Section, k string) ([]zigbee.ClusterID, bool) {
+	return SliceDecoder(ClusterIDDecoder)(s, k)
+}
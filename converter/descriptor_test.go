@@ -0,0 +1,76 @@
+package converter
+
+import (
+	"github.com/shimmeringbee/persistence/impl/memory"
+	"github.com/shimmeringbee/zigbee"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNodeDescription(t *testing.T) {
+	t.Run("stored and retrieved", func(t *testing.T) {
+		s := memory.New()
+
+		expected := zigbee.NodeDescription{LogicalType: zigbee.EndDevice, ManufacturerCode: 0x117c}
+
+		Store(s, Key, expected, NodeDescriptionEncoder)
+
+		actual, found := Retrieve(s, Key, NodeDescriptionDecoder)
+		assert.True(t, found)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("incomplete descriptions are not retrieved", func(t *testing.T) {
+		s := memory.New()
+		LogicalTypeEncoder(s.Section(Key), "logicalType", zigbee.Router)
+
+		_, found := Retrieve(s, Key, NodeDescriptionDecoder)
+		assert.False(t, found)
+	})
+}
+
+func TestEndpointDescription(t *testing.T) {
+	t.Run("stored and retrieved", func(t *testing.T) {
+		s := memory.New()
+
+		expected := zigbee.EndpointDescription{
+			Endpoint:       1,
+			ProfileID:      zigbee.ProfileHomeAutomation,
+			DeviceID:       0x0402,
+			DeviceVersion:  1,
+			InClusterList:  []zigbee.ClusterID{0x0000, 0x0001, 0x0003, 0x0500, 0x0b05, 0x0020, 0x0402, 0x0406, 0x0019, 0x0201, 0xfc00},
+			OutClusterList: []zigbee.ClusterID{0x0019},
+		}
+
+		Store(s, Key, expected, EndpointDescriptionEncoder)
+
+		actual, found := Retrieve(s, Key, EndpointDescriptionDecoder)
+		assert.True(t, found)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("stored and retrieved without clusters", func(t *testing.T) {
+		s := memory.New()
+
+		expected := zigbee.EndpointDescription{Endpoint: 2, ProfileID: zigbee.ProfileHomeAutomation}
+
+		Store(s, Key, expected, EndpointDescriptionEncoder)
+
+		actual, found := Retrieve(s, Key, EndpointDescriptionDecoder)
+		assert.True(t, found)
+		assert.Equal(t, expected, actual)
+	})
+}
+
+func TestClusterIDList(t *testing.T) {
+	t.Run("overwriting a longer list does not leave stale entries", func(t *testing.T) {
+		s := memory.New()
+
+		Store(s, Key, []zigbee.ClusterID{1, 2, 3}, ClusterIDListEncoder)
+		Store(s, Key, []zigbee.ClusterID{4}, ClusterIDListEncoder)
+
+		actual, found := Retrieve(s, Key, ClusterIDListDecoder)
+		assert.True(t, found)
+		assert.Equal(t, []zigbee.ClusterID{4}, actual)
+	})
+}
@@ -0,0 +1,107 @@
+package converter
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"github.com/shimmeringbee/persistence"
+)
+
+func TextEncoder[T any, PT interface {
+	*T
+	encoding.TextMarshaler
+}](s persistence.Section, k string, v T) {
+	_ = TextStrictEncoder[T, PT](s, k, v)
+}
+
+func TextStrictEncoder[T any, PT interface {
+	*T
+	encoding.TextMarshaler
+}](s persistence.Section, k string, v T) error {
+	if b, err := PT(&v).MarshalText(); err != nil {
+		return fmt.Errorf("%q: %w", k, err)
+	} else {
+		s.Set(k, string(b))
+		return nil
+	}
+}
+
+func TextDecoder[T any, PT interface {
+	*T
+	encoding.TextUnmarshaler
+}](s persistence.Section, k string) (T, bool) {
+	var v T
+
+	if ev, found := s.String(k); found {
+		if err := PT(&v).UnmarshalText([]byte(ev)); err != nil {
+			return *new(T), false
+		} else {
+			return v, true
+		}
+	} else {
+		return v, false
+	}
+}
+
+func BinaryEncoder[T any, PT interface {
+	*T
+	encoding.BinaryMarshaler
+}](s persistence.Section, k string, v T) {
+	_ = BinaryStrictEncoder[T, PT](s, k, v)
+}
+
+func BinaryStrictEncoder[T any, PT interface {
+	*T
+	encoding.BinaryMarshaler
+}](s persistence.Section, k string, v T) error {
+	if b, err := PT(&v).MarshalBinary(); err != nil {
+		return fmt.Errorf("%q: %w", k, err)
+	} else {
+		s.Set(k, b)
+		return nil
+	}
+}
+
+func BinaryDecoder[T any, PT interface {
+	*T
+	encoding.BinaryUnmarshaler
+}](s persistence.Section, k string) (T, bool) {
+	var v T
+
+	if ev, found := s.Bytes(k); found {
+		if err := PT(&v).UnmarshalBinary(ev); err != nil {
+			return *new(T), false
+		} else {
+			return v, true
+		}
+	} else {
+		return v, false
+	}
+}
+
+func JSONEncoder[T any](s persistence.Section, k string, v T) {
+	_ = JSONStrictEncoder(s, k, v)
+}
+
+func JSONStrictEncoder[T any](s persistence.Section, k string, v T) error {
+	if b, err := json.Marshal(v); err != nil {
+		return fmt.Errorf("%q: %w", k, err)
+	} else {
+		s.Set(k, string(b))
+		return nil
+	}
+}
+
+func JSONDecoder[T any](s persistence.Section, k string) (T, bool) {
+	var v T
+
+	if ev, found := s.String(k); found {
+		if err := json.Unmarshal([]byte(ev), &v); err != nil {
+			return *new(T), false
+		} else {
+			return v, true
+		}
+	} else {
+		return v, false
+	}
+}
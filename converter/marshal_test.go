@@ -0,0 +1,194 @@
+package converter
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/shimmeringbee/persistence/impl/memory"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"net"
+	"net/netip"
+	"net/url"
+	"testing"
+	"time"
+)
+
+var errUnmarshalable = errors.New("unmarshalable")
+
+type unmarshalable struct{}
+
+func (unmarshalable) MarshalText() ([]byte, error) {
+	return nil, errUnmarshalable
+}
+
+func (unmarshalable) MarshalBinary() ([]byte, error) {
+	return nil, errUnmarshalable
+}
+
+func TestText(t *testing.T) {
+	t.Run("netip.Addr stored and retrieved", func(t *testing.T) {
+		s := memory.New()
+
+		expected := netip.MustParseAddr("fe80::1%eth0")
+
+		Store(s, Key, expected, TextEncoder[netip.Addr])
+
+		stored, _ := s.String(Key)
+		assert.Equal(t, "fe80::1%eth0", stored)
+
+		actual, found := Retrieve(s, Key, TextDecoder[netip.Addr])
+		assert.True(t, found)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("net.IP stored and retrieved", func(t *testing.T) {
+		s := memory.New()
+
+		expected := net.ParseIP("192.168.1.1")
+
+		Store(s, Key, expected, TextEncoder[net.IP])
+
+		actual, found := Retrieve(s, Key, TextDecoder[net.IP])
+		assert.True(t, found)
+		assert.True(t, expected.Equal(actual))
+	})
+
+	t.Run("big.Int stored and retrieved", func(t *testing.T) {
+		s := memory.New()
+
+		expected, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+
+		Store(s, Key, *expected, TextEncoder[big.Int])
+
+		actual, found := Retrieve(s, Key, TextDecoder[big.Int])
+		assert.True(t, found)
+		assert.Equal(t, 0, expected.Cmp(&actual))
+	})
+
+	t.Run("unmarshalable text leaves the key untouched", func(t *testing.T) {
+		s := memory.New()
+		s.Set(Key, "previous")
+
+		assert.NotPanics(t, func() {
+			Store(s, Key, unmarshalable{}, TextEncoder[unmarshalable])
+		})
+
+		stored, _ := s.String(Key)
+		assert.Equal(t, "previous", stored)
+	})
+
+	t.Run("unmarshalable text is reported by the strict encoder", func(t *testing.T) {
+		s := memory.New()
+
+		err := TextStrictEncoder[unmarshalable](s, Key, unmarshalable{})
+		assert.ErrorIs(t, err, errUnmarshalable)
+		assert.False(t, s.Exists(Key))
+	})
+
+	t.Run("malformed text is not retrieved", func(t *testing.T) {
+		s := memory.New()
+		s.Set(Key, "not an address")
+
+		_, found := Retrieve(s, Key, TextDecoder[netip.Addr])
+		assert.False(t, found)
+	})
+}
+
+func TestBinary(t *testing.T) {
+	t.Run("url.URL stored and retrieved", func(t *testing.T) {
+		s := memory.New()
+
+		expected, _ := url.Parse("https://example.com/path?query=1#fragment")
+
+		Store(s, Key, *expected, BinaryEncoder[url.URL])
+
+		_, isBytes := s.Bytes(Key)
+		assert.True(t, isBytes)
+
+		actual, found := Retrieve(s, Key, BinaryDecoder[url.URL])
+		assert.True(t, found)
+		assert.Equal(t, *expected, actual)
+	})
+
+	t.Run("time.Time stored and retrieved", func(t *testing.T) {
+		s := memory.New()
+
+		expected := time.Date(2024, 5, 9, 21, 6, 44, 123456789, time.FixedZone("BST", 3600))
+
+		Store(s, Key, expected, BinaryEncoder[time.Time])
+
+		actual, found := Retrieve(s, Key, BinaryDecoder[time.Time])
+		assert.True(t, found)
+		assert.True(t, expected.Equal(actual))
+	})
+
+	t.Run("unmarshalable binary leaves the key untouched", func(t *testing.T) {
+		s := memory.New()
+		s.Set(Key, []byte{0x01})
+
+		assert.NotPanics(t, func() {
+			Store(s, Key, unmarshalable{}, BinaryEncoder[unmarshalable])
+		})
+
+		stored, _ := s.Bytes(Key)
+		assert.Equal(t, []byte{0x01}, stored)
+
+		err := BinaryStrictEncoder[unmarshalable](s, Key, unmarshalable{})
+		assert.ErrorIs(t, err, errUnmarshalable)
+	})
+}
+
+func TestJSON(t *testing.T) {
+	type config struct {
+		Name     string
+		Channels []int
+		Extra    map[string]int `json:",omitempty"`
+	}
+
+	t.Run("stored and retrieved", func(t *testing.T) {
+		s := memory.New()
+
+		expected := config{Name: "coordinator", Channels: []int{11, 15}}
+
+		Store(s, Key, expected, JSONEncoder[config])
+
+		stored, _ := s.String(Key)
+		assert.JSONEq(t, `{"Name":"coordinator","Channels":[11,15]}`, stored)
+
+		actual, found := Retrieve(s, Key, JSONDecoder[config])
+		assert.True(t, found)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("malformed json is not retrieved", func(t *testing.T) {
+		s := memory.New()
+		s.Set(Key, "{")
+
+		_, found := Retrieve(s, Key, JSONDecoder[config])
+		assert.False(t, found)
+	})
+
+	t.Run("unmarshalable values leave the key untouched", func(t *testing.T) {
+		s := memory.New()
+		s.Set(Key, "previous")
+
+		assert.NotPanics(t, func() {
+			Store(s, Key, make(chan int), JSONEncoder[chan int])
+		})
+
+		stored, _ := s.String(Key)
+		assert.Equal(t, "previous", stored)
+	})
+
+	t.Run("unmarshalable values are reported by the strict encoder", func(t *testing.T) {
+		s := memory.New()
+
+		var unsupported *json.UnsupportedTypeError
+
+		err := JSONStrictEncoder(s, Key, make(chan int))
+		assert.ErrorAs(t, err, &unsupported)
+		assert.False(t, s.Exists(Key))
+
+		assert.NoError(t, JSONStrictEncoder(s, Key, 1))
+	})
+}
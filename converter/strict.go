@@ -0,0 +1,206 @@
+package converter
+
+import (
+	"errors"
+	"fmt"
+	"github.com/shimmeringbee/persistence"
+	"github.com/shimmeringbee/zcl"
+	"github.com/shimmeringbee/zigbee"
+	"math"
+	"strconv"
+	"time"
+)
+
+var ErrMissing = errors.New("value is missing")
+var ErrWrongType = errors.New("value has the wrong type")
+var ErrMalformed = errors.New("value is malformed")
+var ErrOutOfRange = errors.New("value is out of range")
+
+type StrictDecoder[T any] func(persistence.Section, string) (T, error)
+
+func RetrieveStrict[T any](section persistence.Section, key string, dec func(persistence.Section, string) (T, error), defValue ...T) (T, error) {
+	if v, err := dec(section, key); err == nil {
+		return v, nil
+	} else {
+		if len(defValue) > 0 {
+			return defValue[0], err
+		} else {
+			return *new(T), err
+		}
+	}
+}
+
+func Lenient[T any](dec StrictDecoder[T]) Decoder[T] {
+	return func(s persistence.Section, k string) (T, bool) {
+		v, err := dec(s, k)
+		return v, err == nil
+	}
+}
+
+func Strict[T any](dec Decoder[T], expected persistence.ValueType) StrictDecoder[T] {
+	return func(s persistence.Section, k string) (T, error) {
+		if err := checkType(s, k, expected); err != nil {
+			return *new(T), err
+		}
+
+		if v, ok := dec(s, k); ok {
+			return v, nil
+		}
+
+		return *new(T), fmt.Errorf("%q: %w", k, ErrMalformed)
+	}
+}
+
+func checkType(s persistence.Section, k string, expected persistence.ValueType) error {
+	if !s.Exists(k) {
+		if _, found := s.SectionIfExists(k); found {
+			return fmt.Errorf("%q: expected value of type %d, found section: %w", k, expected, ErrWrongType)
+		}
+
+		return fmt.Errorf("%q: %w", k, ErrMissing)
+	}
+
+	if actual := s.Type(k); actual != expected {
+		return fmt.Errorf("%q: expected value of type %d, found type %d: %w", k, expected, actual, ErrWrongType)
+	}
+
+	return nil
+}
+
+func strictInt[T integer](s persistence.Section, k string, min int64, max int64) (T, error) {
+	if err := checkType(s, k, persistence.Int); err != nil {
+		return 0, err
+	}
+
+	v, _ := s.Int(k)
+
+	if v < min || v > max {
+		return 0, fmt.Errorf("%q: %d not within %d to %d: %w", k, v, min, max, ErrOutOfRange)
+	}
+
+	return T(v), nil
+}
+
+func strictHex[T integer](s persistence.Section, k string) (T, error) {
+	if err := checkType(s, k, persistence.String); err != nil {
+		return 0, err
+	}
+
+	ev, _ := s.String(k)
+
+	if v, err := strconv.ParseUint(ev, 16, 64); errors.Is(err, strconv.ErrRange) {
+		return 0, fmt.Errorf("%q: %w: %w", k, err, ErrOutOfRange)
+	} else if err != nil {
+		return 0, fmt.Errorf("%q: %w: %w", k, err, ErrMalformed)
+	} else {
+		return T(v), nil
+	}
+}
+
+func AttributeIDStrictDecoder(s persistence.Section, k string) (zcl.AttributeID, error) {
+	return strictInt[zcl.AttributeID](s, k, 0, math.MaxUint16)
+}
+
+func AttributeDataTypeStrictDecoder(s persistence.Section, k string) (zcl.AttributeDataType, error) {
+	return strictInt[zcl.AttributeDataType](s, k, 0, math.MaxUint8)
+}
+
+func IEEEStrictDecoder(s persistence.Section, k string) (zigbee.IEEEAddress, error) {
+	return strictHex[zigbee.IEEEAddress](s, k)
+}
+
+func NetworkAddressStrictDecoder(s persistence.Section, k string) (zigbee.NetworkAddress, error) {
+	return strictInt[zigbee.NetworkAddress](s, k, 0, math.MaxUint16)
+}
+
+func LogicalTypeStrictDecoder(s persistence.Section, k string) (zigbee.LogicalType, error) {
+	return strictInt[zigbee.LogicalType](s, k, 0, math.MaxUint8)
+}
+
+func ClusterIDStrictDecoder(s persistence.Section, k string) (zigbee.ClusterID, error) {
+	return strictInt[zigbee.ClusterID](s, k, 0, math.MaxUint16)
+}
+
+func EndpointStrictDecoder(s persistence.Section, k string) (zigbee.Endpoint, error) {
+	return strictInt[zigbee.Endpoint](s, k, 0, math.MaxUint8)
+}
+
+func PANIDStrictDecoder(s persistence.Section, k string) (zigbee.PANID, error) {
+	return strictInt[zigbee.PANID](s, k, 0, math.MaxUint16)
+}
+
+func ExtendedPANIDStrictDecoder(s persistence.Section, k string) (zigbee.ExtendedPANID, error) {
+	return strictHex[zigbee.ExtendedPANID](s, k)
+}
+
+func NetworkKeyStrictDecoder(s persistence.Section, k string) (zigbee.NetworkKey, error) {
+	if err := checkType(s, k, persistence.Bytes); err != nil {
+		return zigbee.NetworkKey{}, err
+	}
+
+	ev, _ := s.Bytes(k)
+
+	if len(ev) != len(zigbee.NetworkKey{}) {
+		return zigbee.NetworkKey{}, fmt.Errorf("%q: expected %d bytes, found %d: %w", k, len(zigbee.NetworkKey{}), len(ev), ErrMalformed)
+	}
+
+	return zigbee.NetworkKey(ev), nil
+}
+
+func ChannelStrictDecoder(s persistence.Section, k string) (uint8, error) {
+	return strictInt[uint8](s, k, int64(zigbee.Channels[0]), int64(zigbee.Channels[len(zigbee.Channels)-1]))
+}
+
+func ProfileIDStrictDecoder(s persistence.Section, k string) (zigbee.ProfileID, error) {
+	return strictInt[zigbee.ProfileID](s, k, 0, math.MaxUint16)
+}
+
+func ManufacturerCodeStrictDecoder(s persistence.Section, k string) (zigbee.ManufacturerCode, error) {
+	return strictInt[zigbee.ManufacturerCode](s, k, 0, math.MaxUint16)
+}
+
+func TimeStrictDecoder(s persistence.Section, k string) (time.Time, error) {
+	if err := checkType(s, k, persistence.Int); err != nil {
+		return time.Time{}, err
+	}
+
+	ev, _ := s.Int(k)
+	return time.UnixMilli(ev), nil
+}
+
+func DurationStrictDecoder(s persistence.Section, k string) (time.Duration, error) {
+	ev, err := strictInt[int64](s, k, math.MinInt64/int64(time.Millisecond), math.MaxInt64/int64(time.Millisecond))
+	return time.Duration(ev) * time.Millisecond, err
+}
+
+func RFC3339TimeStrictDecoder(s persistence.Section, k string) (time.Time, error) {
+	if err := checkType(s, k, persistence.String); err != nil {
+		return time.Time{}, err
+	}
+
+	ev, _ := s.String(k)
+
+	if t, err := time.Parse(time.RFC3339Nano, ev); err != nil {
+		return time.Time{}, fmt.Errorf("%q: %w: %w", k, err, ErrMalformed)
+	} else {
+		return t, nil
+	}
+}
+
+func UnixNanoTimeStrictDecoder(s persistence.Section, k string) (time.Time, error) {
+	if err := checkType(s, k, persistence.Int); err != nil {
+		return time.Time{}, err
+	}
+
+	ev, _ := s.Int(k)
+	return time.Unix(0, ev), nil
+}
+
+func ZCLUTCTimeStrictDecoder(s persistence.Section, k string) (time.Time, error) {
+	ev, err := strictInt[int64](s, k, 0, math.MaxUint32)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Unix(zclEpoch.Unix()+ev, 0).UTC(), nil
+}
@@ -0,0 +1,169 @@
+package converter
+
+import (
+	"github.com/shimmeringbee/persistence"
+	"github.com/shimmeringbee/persistence/impl/memory"
+	"github.com/shimmeringbee/zigbee"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRetrieveStrict(t *testing.T) {
+	t.Run("stored and retrieved", func(t *testing.T) {
+		s := memory.New()
+
+		expected := zigbee.Endpoint(1)
+
+		Store(s, Key, expected, EndpointEncoder)
+
+		actual, err := RetrieveStrict(s, Key, EndpointStrictDecoder)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("missing value returns the default and an error", func(t *testing.T) {
+		s := memory.New()
+
+		actual, err := RetrieveStrict(s, Key, EndpointStrictDecoder, 5)
+		assert.ErrorIs(t, err, ErrMissing)
+		assert.Equal(t, zigbee.Endpoint(5), actual)
+	})
+
+	t.Run("value of the wrong type is reported", func(t *testing.T) {
+		s := memory.New()
+		s.Set(Key, "1")
+
+		_, err := RetrieveStrict(s, Key, EndpointStrictDecoder)
+		assert.ErrorIs(t, err, ErrWrongType)
+	})
+
+	t.Run("section in place of a value is reported as the wrong type", func(t *testing.T) {
+		s := memory.New()
+		s.Section(Key)
+
+		_, err := RetrieveStrict(s, Key, EndpointStrictDecoder)
+		assert.ErrorIs(t, err, ErrWrongType)
+	})
+
+	t.Run("value out of range for the type is reported rather than truncated", func(t *testing.T) {
+		s := memory.New()
+		s.Set(Key, int64(70000))
+
+		_, err := RetrieveStrict(s, Key, NetworkAddressStrictDecoder)
+		assert.ErrorIs(t, err, ErrOutOfRange)
+
+		lenient, found := Retrieve(s, Key, NetworkAddressDecoder)
+		assert.True(t, found)
+		assert.Equal(t, zigbee.NetworkAddress(70000&0xffff), lenient)
+	})
+
+	t.Run("malformed value is reported", func(t *testing.T) {
+		s := memory.New()
+		s.Set(Key, "not hex")
+
+		_, err := RetrieveStrict(s, Key, IEEEStrictDecoder)
+		assert.ErrorIs(t, err, ErrMalformed)
+	})
+
+	t.Run("lenient decoders distinguish missing, wrong type and malformed values when made strict", func(t *testing.T) {
+		s := memory.New()
+
+		_, err := RetrieveStrict(s, Key, Strict(IEEEDecoder, persistence.String))
+		assert.ErrorIs(t, err, ErrMissing)
+
+		s.Set(Key, "not hex")
+
+		_, err = RetrieveStrict(s, Key, Strict(IEEEDecoder, persistence.String))
+		assert.ErrorIs(t, err, ErrMalformed)
+
+		s.Set(Key, int64(1))
+
+		_, err = RetrieveStrict(s, Key, Strict(IEEEDecoder, persistence.String))
+		assert.ErrorIs(t, err, ErrWrongType)
+
+		s.Delete(Key)
+		s.Section(Key)
+
+		_, err = RetrieveStrict(s, Key, Strict(IEEEDecoder, persistence.String))
+		assert.ErrorIs(t, err, ErrWrongType)
+
+		s.SectionDelete(Key)
+
+		Store(s, Key, zigbee.IEEEAddress(0x00124b0012345678), IEEEEncoder)
+
+		actual, err := RetrieveStrict(s, Key, Strict(IEEEDecoder, persistence.String))
+		assert.NoError(t, err)
+		assert.Equal(t, zigbee.IEEEAddress(0x00124b0012345678), actual)
+	})
+
+	t.Run("overlong hex value is reported as out of range", func(t *testing.T) {
+		s := memory.New()
+		s.Set(Key, "1ffffffffffffffff")
+
+		_, err := RetrieveStrict(s, Key, ExtendedPANIDStrictDecoder)
+		assert.ErrorIs(t, err, ErrOutOfRange)
+	})
+
+	t.Run("network key of the wrong length is reported as malformed", func(t *testing.T) {
+		s := memory.New()
+		s.Set(Key, []byte{0x01})
+
+		_, err := RetrieveStrict(s, Key, NetworkKeyStrictDecoder)
+		assert.ErrorIs(t, err, ErrMalformed)
+	})
+
+	t.Run("channel outside of the zigbee range is reported", func(t *testing.T) {
+		s := memory.New()
+		s.Set(Key, int64(27))
+
+		_, err := RetrieveStrict(s, Key, ChannelStrictDecoder)
+		assert.ErrorIs(t, err, ErrOutOfRange)
+	})
+
+	t.Run("malformed time is reported", func(t *testing.T) {
+		s := memory.New()
+		s.Set(Key, "yesterday")
+
+		_, err := RetrieveStrict(s, Key, RFC3339TimeStrictDecoder)
+		assert.ErrorIs(t, err, ErrMalformed)
+	})
+
+	t.Run("duration that would overflow is reported", func(t *testing.T) {
+		s := memory.New()
+		s.Set(Key, int64(1)<<62)
+
+		_, err := RetrieveStrict(s, Key, DurationStrictDecoder)
+		assert.ErrorIs(t, err, ErrOutOfRange)
+	})
+
+	t.Run("strict decoders round trip with their encoders", func(t *testing.T) {
+		s := memory.New()
+
+		Store(s, "ieee", zigbee.IEEEAddress(0x00124b0012345678), IEEEEncoder)
+		Store(s, "key", zigbee.TCLinkKey, NetworkKeyEncoder)
+		Store(s, "time", time.Date(2024, 5, 9, 21, 6, 44, 0, time.UTC), ZCLUTCTimeEncoder)
+
+		ieee, err := RetrieveStrict(s, "ieee", IEEEStrictDecoder)
+		assert.NoError(t, err)
+		assert.Equal(t, zigbee.IEEEAddress(0x00124b0012345678), ieee)
+
+		key, err := RetrieveStrict(s, "key", NetworkKeyStrictDecoder)
+		assert.NoError(t, err)
+		assert.Equal(t, zigbee.TCLinkKey, key)
+
+		tm, err := RetrieveStrict(s, "time", ZCLUTCTimeStrictDecoder)
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 5, 9, 21, 6, 44, 0, time.UTC), tm)
+	})
+}
+
+func TestLenient(t *testing.T) {
+	t.Run("strict decoder errors become not found", func(t *testing.T) {
+		s := memory.New()
+		s.Set(Key, int64(300))
+
+		_, found := Retrieve(s, Key, Lenient(EndpointStrictDecoder))
+		assert.False(t, found)
+	})
+}
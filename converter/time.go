@@ -28,3 +28,89 @@ func DurationDecoder(s persistence.Section, k string) (time.Duration, bool) {
 		return time.Duration(0), false
 	}
 }
+
+func RFC3339TimeEncoder(s persistence.Section, k string, v time.Time) {
+	s.Set(k, v.Format(time.RFC3339Nano))
+}
+
+func RFC3339TimeDecoder(s persistence.Section, k string) (time.Time, bool) {
+	if ev, found := s.String(k); found {
+		if t, err := time.Parse(time.RFC3339Nano, ev); err != nil {
+			return time.Time{}, false
+		} else {
+			return t, true
+		}
+	} else {
+		return time.Time{}, false
+	}
+}
+
+func UnixNanoTimeEncoder(s persistence.Section, k string, v time.Time) {
+	s.Set(k, v.UnixNano())
+}
+
+func UnixNanoTimeDecoder(s persistence.Section, k string) (time.Time, bool) {
+	if ev, found := s.Int(k); found {
+		return time.Unix(0, ev), true
+	} else {
+		return time.Time{}, false
+	}
+}
+
+func ZonedTimeEncoder(s persistence.Section, k string, v time.Time) {
+	_, offset := v.Zone()
+
+	s.SectionDelete(k)
+	ss := s.Section(k)
+
+	ss.Set("seconds", v.Unix())
+	ss.Set("nanos", int64(v.Nanosecond()))
+	ss.Set("zone", v.Location().String())
+	ss.Set("offset", int64(offset))
+}
+
+func ZonedTimeDecoder(s persistence.Section, k string) (time.Time, bool) {
+	ss, found := s.SectionIfExists(k)
+	if !found {
+		return time.Time{}, false
+	}
+
+	seconds, sFound := ss.Int("seconds")
+	nanos, nFound := ss.Int("nanos")
+	zone, zFound := ss.String("zone")
+
+	if !sFound || !nFound || !zFound {
+		return time.Time{}, false
+	}
+
+	t := time.Unix(seconds, nanos)
+	offset, oFound := ss.Int("offset")
+
+	if zone != "" {
+		if loc, err := time.LoadLocation(zone); err == nil {
+			if _, actual := t.In(loc).Zone(); !oFound || int64(actual) == offset {
+				return t.In(loc), true
+			}
+		}
+	}
+
+	if oFound {
+		return t.In(time.FixedZone(zone, int(offset))), true
+	} else {
+		return time.Time{}, false
+	}
+}
+
+var zclEpoch = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+func ZCLUTCTimeEncoder(s persistence.Section, k string, v time.Time) {
+	s.Set(k, v.Unix()-zclEpoch.Unix())
+}
+
+func ZCLUTCTimeDecoder(s persistence.Section, k string) (time.Time, bool) {
+	if ev, found := s.Int(k); found {
+		return time.Unix(zclEpoch.Unix()+ev, 0).UTC(), true
+	} else {
+		return time.Time{}, false
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"testing"
 	"time"
+	_ "time/tzdata"
 )
 
 const Key = "key"
@@ -23,6 +24,137 @@ func TestTime(t *testing.T) {
 	})
 }
 
+func TestRFC3339Time(t *testing.T) {
+	t.Run("time is stored and retrieved to the nanosecond with its offset", func(t *testing.T) {
+		s := memory.New()
+
+		expected := time.Date(2024, 5, 9, 21, 6, 44, 123456789, time.FixedZone("", 3600))
+
+		Store(s, Key, expected, RFC3339TimeEncoder)
+
+		stored, _ := s.String(Key)
+		assert.Equal(t, "2024-05-09T21:06:44.123456789+01:00", stored)
+
+		actual, found := Retrieve(s, Key, RFC3339TimeDecoder)
+		assert.True(t, found)
+		assert.True(t, expected.Equal(actual))
+
+		_, offset := actual.Zone()
+		assert.Equal(t, 3600, offset)
+	})
+
+	t.Run("malformed time is not retrieved", func(t *testing.T) {
+		s := memory.New()
+		s.Set(Key, "yesterday")
+
+		_, found := Retrieve(s, Key, RFC3339TimeDecoder)
+		assert.False(t, found)
+	})
+}
+
+func TestUnixNanoTime(t *testing.T) {
+	t.Run("time is stored and retrieved to the nanosecond", func(t *testing.T) {
+		s := memory.New()
+
+		expected := time.Unix(1715288804, 123456789)
+
+		Store(s, Key, expected, UnixNanoTimeEncoder)
+
+		actual, found := Retrieve(s, Key, UnixNanoTimeDecoder)
+		assert.True(t, found)
+		assert.Equal(t, expected, actual)
+	})
+}
+
+func TestZonedTime(t *testing.T) {
+	t.Run("time is stored and retrieved with its named location", func(t *testing.T) {
+		s := memory.New()
+
+		london, err := time.LoadLocation("Europe/London")
+		assert.NoError(t, err)
+
+		expected := time.Date(2024, 5, 9, 21, 6, 44, 123456789, london)
+
+		Store(s, Key, expected, ZonedTimeEncoder)
+
+		actual, found := Retrieve(s, Key, ZonedTimeDecoder)
+		assert.True(t, found)
+		assert.True(t, expected.Equal(actual))
+		assert.Equal(t, "Europe/London", actual.Location().String())
+		assert.Equal(t, expected.String(), actual.String())
+	})
+
+	t.Run("time in a fixed zone is stored and retrieved with its offset", func(t *testing.T) {
+		s := memory.New()
+
+		expected := time.Date(2024, 5, 9, 21, 6, 44, 1, time.FixedZone("XYZ", -7*3600))
+
+		Store(s, Key, expected, ZonedTimeEncoder)
+
+		actual, found := Retrieve(s, Key, ZonedTimeDecoder)
+		assert.True(t, found)
+		assert.Equal(t, expected.String(), actual.String())
+	})
+
+	t.Run("time in an unnamed fixed zone is stored and retrieved with its offset", func(t *testing.T) {
+		s := memory.New()
+
+		expected := time.Date(2024, 5, 9, 21, 6, 44, 0, time.FixedZone("", 3600))
+
+		Store(s, Key, expected, ZonedTimeEncoder)
+
+		actual, found := Retrieve(s, Key, ZonedTimeDecoder)
+		assert.True(t, found)
+		assert.True(t, expected.Equal(actual))
+		assert.Equal(t, expected.String(), actual.String())
+
+		_, offset := actual.Zone()
+		assert.Equal(t, 3600, offset)
+	})
+
+	t.Run("time parsed with a numeric offset is stored and retrieved with its offset", func(t *testing.T) {
+		s := memory.New()
+
+		expected, err := time.Parse(time.RFC3339, "2024-05-09T21:06:44+01:00")
+		assert.NoError(t, err)
+
+		Store(s, Key, expected, ZonedTimeEncoder)
+
+		actual, found := Retrieve(s, Key, ZonedTimeDecoder)
+		assert.True(t, found)
+		assert.Equal(t, expected.String(), actual.String())
+	})
+
+	t.Run("time before the unix epoch is stored and retrieved", func(t *testing.T) {
+		s := memory.New()
+
+		expected := time.Date(1969, 12, 31, 23, 59, 59, 999999999, time.UTC)
+
+		Store(s, Key, expected, ZonedTimeEncoder)
+
+		actual, found := Retrieve(s, Key, ZonedTimeDecoder)
+		assert.True(t, found)
+		assert.Equal(t, expected, actual)
+	})
+}
+
+func TestZCLUTCTime(t *testing.T) {
+	t.Run("time is stored as seconds since 2000 and retrieved", func(t *testing.T) {
+		s := memory.New()
+
+		expected := time.Date(2024, 5, 9, 21, 6, 44, 0, time.UTC)
+
+		Store(s, Key, expected, ZCLUTCTimeEncoder)
+
+		stored, _ := s.Int(Key)
+		assert.Equal(t, expected.Unix()-946684800, stored)
+
+		actual, found := Retrieve(s, Key, ZCLUTCTimeDecoder)
+		assert.True(t, found)
+		assert.Equal(t, expected, actual)
+	})
+}
+
 func TestDuration(t *testing.T) {
 	t.Run("duration is stored and retrieved to the millisecond level", func(t *testing.T) {
 		s := memory.New()
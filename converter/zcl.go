@@ -0,0 +1,276 @@
+package converter
+
+import (
+	"github.com/shimmeringbee/persistence"
+	"github.com/shimmeringbee/zcl"
+	"github.com/shimmeringbee/zigbee"
+	"strconv"
+)
+
+func AttributeDataTypeValueEncoder(s persistence.Section, k string, v zcl.AttributeDataTypeValue) {
+	s.SectionDelete(k)
+	ss := s.Section(k)
+
+	AttributeDataTypeEncoder(ss, "dataType", v.DataType)
+	zclValueEncoder(ss, "value", v.DataType, v.Value)
+}
+
+func AttributeDataTypeValueDecoder(s persistence.Section, k string) (zcl.AttributeDataTypeValue, bool) {
+	ss, found := s.SectionIfExists(k)
+	if !found {
+		return zcl.AttributeDataTypeValue{}, false
+	}
+
+	dt, found := AttributeDataTypeDecoder(ss, "dataType")
+	if !found {
+		return zcl.AttributeDataTypeValue{}, false
+	}
+
+	if v, found := zclValueDecoder(ss, "value", dt); found {
+		return zcl.AttributeDataTypeValue{DataType: dt, Value: v}, true
+	} else {
+		return zcl.AttributeDataTypeValue{}, false
+	}
+}
+
+func zclValueEncoder(s persistence.Section, k string, dt zcl.AttributeDataType, v any) {
+	switch dt {
+	case zcl.TypeData8, zcl.TypeData16, zcl.TypeData24, zcl.TypeData32, zcl.TypeData40, zcl.TypeData48, zcl.TypeData56, zcl.TypeData64:
+		if b, ok := v.([]byte); ok {
+			s.Set(k, b)
+		}
+	case zcl.TypeBoolean:
+		if b, ok := v.(bool); ok {
+			s.Set(k, b)
+		}
+	case zcl.TypeBitmap8, zcl.TypeBitmap16, zcl.TypeBitmap24, zcl.TypeBitmap32, zcl.TypeBitmap40, zcl.TypeBitmap48, zcl.TypeBitmap56, zcl.TypeBitmap64,
+		zcl.TypeUnsignedInt8, zcl.TypeUnsignedInt16, zcl.TypeUnsignedInt24, zcl.TypeUnsignedInt32, zcl.TypeUnsignedInt40, zcl.TypeUnsignedInt48, zcl.TypeUnsignedInt56, zcl.TypeUnsignedInt64,
+		zcl.TypeEnum8, zcl.TypeEnum16:
+		if n, ok := zclUint(v); ok {
+			s.Set(k, n)
+		}
+	case zcl.TypeSignedInt8, zcl.TypeSignedInt16, zcl.TypeSignedInt24, zcl.TypeSignedInt32, zcl.TypeSignedInt40, zcl.TypeSignedInt48, zcl.TypeSignedInt56, zcl.TypeSignedInt64:
+		if n, ok := zclInt(v); ok {
+			s.Set(k, n)
+		}
+	case zcl.TypeFloatSemi, zcl.TypeFloatSingle:
+		if f, ok := v.(float32); ok {
+			s.Set(k, float64(f))
+		}
+	case zcl.TypeFloatDouble:
+		if f, ok := v.(float64); ok {
+			s.Set(k, f)
+		}
+	case zcl.TypeStringOctet8, zcl.TypeStringOctet16, zcl.TypeStringCharacter8, zcl.TypeStringCharacter16:
+		if str, ok := v.(string); ok {
+			s.Set(k, str)
+		}
+	case zcl.TypeTimeOfDay:
+		if tod, ok := v.(zcl.TimeOfDay); ok {
+			ss := s.Section(k)
+			ss.Set("hours", int64(tod.Hours))
+			ss.Set("minutes", int64(tod.Minutes))
+			ss.Set("seconds", int64(tod.Seconds))
+			ss.Set("hundredths", int64(tod.Hundredths))
+		}
+	case zcl.TypeDate:
+		if date, ok := v.(zcl.Date); ok {
+			ss := s.Section(k)
+			ss.Set("year", int64(date.Year))
+			ss.Set("month", int64(date.Month))
+			ss.Set("dayOfMonth", int64(date.DayOfMonth))
+			ss.Set("dayOfWeek", int64(date.DayOfWeek))
+		}
+	case zcl.TypeUTCTime:
+		if t, ok := v.(zcl.UTCTime); ok {
+			s.Set(k, int64(t))
+		}
+	case zcl.TypeClusterID:
+		if id, ok := v.(zigbee.ClusterID); ok {
+			ClusterIDEncoder(s, k, id)
+		}
+	case zcl.TypeAttributeID:
+		if id, ok := v.(zcl.AttributeID); ok {
+			AttributeIDEncoder(s, k, id)
+		}
+	case zcl.TypeBACnetOID:
+		if oid, ok := v.(zcl.BACnetOID); ok {
+			s.Set(k, int64(oid))
+		}
+	case zcl.TypeIEEEAddress:
+		if addr, ok := v.(zigbee.IEEEAddress); ok {
+			IEEEEncoder(s, k, addr)
+		}
+	case zcl.TypeSecurityKey128:
+		if key, ok := v.(zigbee.NetworkKey); ok {
+			NetworkKeyEncoder(s, k, key)
+		}
+	case zcl.TypeStructure:
+		if values, ok := v.([]zcl.AttributeDataTypeValue); ok {
+			ss := s.Section(k)
+
+			for i, value := range values {
+				AttributeDataTypeValueEncoder(ss, strconv.Itoa(i), value)
+			}
+		}
+	case zcl.TypeArray, zcl.TypeSet, zcl.TypeBag:
+		if slice, ok := v.(zcl.AttributeSlice); ok {
+			ss := s.Section(k)
+			AttributeDataTypeEncoder(ss, "dataType", slice.DataType)
+			ss.Set("count", int64(len(slice.Values)))
+
+			values := ss.Section("values")
+
+			for i, value := range slice.Values {
+				zclValueEncoder(values, strconv.Itoa(i), slice.DataType, value)
+			}
+		}
+	}
+}
+
+func zclValueDecoder(s persistence.Section, k string, dt zcl.AttributeDataType) (any, bool) {
+	switch dt {
+	case zcl.TypeNull:
+		return nil, true
+	case zcl.TypeData8, zcl.TypeData16, zcl.TypeData24, zcl.TypeData32, zcl.TypeData40, zcl.TypeData48, zcl.TypeData56, zcl.TypeData64:
+		return s.Bytes(k)
+	case zcl.TypeBoolean:
+		return s.Bool(k)
+	case zcl.TypeBitmap8, zcl.TypeBitmap16, zcl.TypeBitmap24, zcl.TypeBitmap32, zcl.TypeBitmap40, zcl.TypeBitmap48, zcl.TypeBitmap56, zcl.TypeBitmap64,
+		zcl.TypeUnsignedInt8, zcl.TypeUnsignedInt16, zcl.TypeUnsignedInt24, zcl.TypeUnsignedInt32, zcl.TypeUnsignedInt40, zcl.TypeUnsignedInt48, zcl.TypeUnsignedInt56, zcl.TypeUnsignedInt64:
+		return s.UInt(k)
+	case zcl.TypeEnum8:
+		n, found := s.UInt(k)
+		return uint8(n), found
+	case zcl.TypeEnum16:
+		n, found := s.UInt(k)
+		return uint16(n), found
+	case zcl.TypeSignedInt8, zcl.TypeSignedInt16, zcl.TypeSignedInt24, zcl.TypeSignedInt32, zcl.TypeSignedInt40, zcl.TypeSignedInt48, zcl.TypeSignedInt56, zcl.TypeSignedInt64:
+		return s.Int(k)
+	case zcl.TypeFloatSemi, zcl.TypeFloatSingle:
+		f, found := s.Float(k)
+		return float32(f), found
+	case zcl.TypeFloatDouble:
+		return s.Float(k)
+	case zcl.TypeStringOctet8, zcl.TypeStringOctet16, zcl.TypeStringCharacter8, zcl.TypeStringCharacter16:
+		return s.String(k)
+	case zcl.TypeTimeOfDay:
+		ss, found := s.SectionIfExists(k)
+		if !found {
+			return nil, false
+		}
+
+		hours, hFound := ss.Int("hours")
+		minutes, mFound := ss.Int("minutes")
+		seconds, sFound := ss.Int("seconds")
+		hundredths, hsFound := ss.Int("hundredths")
+
+		return zcl.TimeOfDay{Hours: uint8(hours), Minutes: uint8(minutes), Seconds: uint8(seconds), Hundredths: uint8(hundredths)}, hFound && mFound && sFound && hsFound
+	case zcl.TypeDate:
+		ss, found := s.SectionIfExists(k)
+		if !found {
+			return nil, false
+		}
+
+		year, yFound := ss.Int("year")
+		month, mFound := ss.Int("month")
+		dayOfMonth, domFound := ss.Int("dayOfMonth")
+		dayOfWeek, dowFound := ss.Int("dayOfWeek")
+
+		return zcl.Date{Year: uint8(year), Month: uint8(month), DayOfMonth: uint8(dayOfMonth), DayOfWeek: uint8(dayOfWeek)}, yFound && mFound && domFound && dowFound
+	case zcl.TypeUTCTime:
+		t, found := s.Int(k)
+		return zcl.UTCTime(t), found
+	case zcl.TypeClusterID:
+		return ClusterIDDecoder(s, k)
+	case zcl.TypeAttributeID:
+		return AttributeIDDecoder(s, k)
+	case zcl.TypeBACnetOID:
+		oid, found := s.Int(k)
+		return zcl.BACnetOID(oid), found
+	case zcl.TypeIEEEAddress:
+		return IEEEDecoder(s, k)
+	case zcl.TypeSecurityKey128:
+		return NetworkKeyDecoder(s, k)
+	case zcl.TypeStructure:
+		ss, found := s.SectionIfExists(k)
+		if !found {
+			return nil, false
+		}
+
+		values := []zcl.AttributeDataTypeValue{}
+
+		for i := 0; ; i++ {
+			value, found := AttributeDataTypeValueDecoder(ss, strconv.Itoa(i))
+			if !found {
+				break
+			}
+
+			values = append(values, value)
+		}
+
+		return values, true
+	case zcl.TypeArray, zcl.TypeSet, zcl.TypeBag:
+		ss, found := s.SectionIfExists(k)
+		if !found {
+			return nil, false
+		}
+
+		elementType, tFound := AttributeDataTypeDecoder(ss, "dataType")
+		count, cFound := ss.Int("count")
+		values, vFound := ss.SectionIfExists("values")
+
+		if !tFound || !cFound || !vFound {
+			return nil, false
+		}
+
+		slice := zcl.AttributeSlice{DataType: elementType, Values: []interface{}{}}
+
+		for i := 0; i < int(count); i++ {
+			value, found := zclValueDecoder(values, strconv.Itoa(i), elementType)
+			if !found {
+				return nil, false
+			}
+
+			slice.Values = append(slice.Values, value)
+		}
+
+		return slice, true
+	default:
+		return nil, false
+	}
+}
+
+func zclUint(v any) (uint64, bool) {
+	switch v := v.(type) {
+	case uint:
+		return uint64(v), true
+	case uint8:
+		return uint64(v), true
+	case uint16:
+		return uint64(v), true
+	case uint32:
+		return uint64(v), true
+	case uint64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+func zclInt(v any) (int64, bool) {
+	switch v := v.(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
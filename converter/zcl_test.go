@@ -0,0 +1,91 @@
+package converter
+
+import (
+	"github.com/shimmeringbee/persistence/impl/memory"
+	"github.com/shimmeringbee/zcl"
+	"github.com/shimmeringbee/zigbee"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAttributeDataTypeValue(t *testing.T) {
+	tests := map[string]zcl.AttributeDataTypeValue{
+		"null":       {DataType: zcl.TypeNull},
+		"data":       {DataType: zcl.TypeData24, Value: []byte{0x01, 0x02, 0x03}},
+		"boolean":    {DataType: zcl.TypeBoolean, Value: true},
+		"bitmap":     {DataType: zcl.TypeBitmap16, Value: uint64(0x8001)},
+		"unsigned":   {DataType: zcl.TypeUnsignedInt64, Value: uint64(0xffffffffffffffff)},
+		"signed":     {DataType: zcl.TypeSignedInt24, Value: int64(-8388608)},
+		"enum8":      {DataType: zcl.TypeEnum8, Value: uint8(0x02)},
+		"enum16":     {DataType: zcl.TypeEnum16, Value: uint16(0x1234)},
+		"single":     {DataType: zcl.TypeFloatSingle, Value: float32(21.5)},
+		"double":     {DataType: zcl.TypeFloatDouble, Value: 3.141592653589793},
+		"string":     {DataType: zcl.TypeStringCharacter8, Value: "lumi.sensor_ht"},
+		"octets":     {DataType: zcl.TypeStringOctet16, Value: "\x00\x01\xff"},
+		"timeOfDay":  {DataType: zcl.TypeTimeOfDay, Value: zcl.TimeOfDay{Hours: 13, Minutes: 37, Seconds: 5, Hundredths: 99}},
+		"date":       {DataType: zcl.TypeDate, Value: zcl.Date{Year: 124, Month: 5, DayOfMonth: 9, DayOfWeek: 4}},
+		"utcTime":    {DataType: zcl.TypeUTCTime, Value: zcl.UTCTime(768787200)},
+		"clusterId":  {DataType: zcl.TypeClusterID, Value: zigbee.ClusterID(0x0402)},
+		"attribute":  {DataType: zcl.TypeAttributeID, Value: zcl.AttributeID(0x0005)},
+		"bacnet":     {DataType: zcl.TypeBACnetOID, Value: zcl.BACnetOID(0x00400001)},
+		"ieee":       {DataType: zcl.TypeIEEEAddress, Value: zigbee.IEEEAddress(0x00124b0012345678)},
+		"key":        {DataType: zcl.TypeSecurityKey128, Value: zigbee.TCLinkKey},
+		"emptyArray": {DataType: zcl.TypeArray, Value: zcl.AttributeSlice{DataType: zcl.TypeUnsignedInt8, Values: []interface{}{}}},
+		"array": {DataType: zcl.TypeArray, Value: zcl.AttributeSlice{
+			DataType: zcl.TypeUnsignedInt16,
+			Values:   []interface{}{uint64(1), uint64(2), uint64(3), uint64(4), uint64(5), uint64(6), uint64(7), uint64(8), uint64(9), uint64(10), uint64(11)},
+		}},
+		"set": {DataType: zcl.TypeSet, Value: zcl.AttributeSlice{
+			DataType: zcl.TypeStringCharacter8,
+			Values:   []interface{}{"a", "b"},
+		}},
+		"emptyStructure": {DataType: zcl.TypeStructure, Value: []zcl.AttributeDataTypeValue{}},
+		"structure": {DataType: zcl.TypeStructure, Value: []zcl.AttributeDataTypeValue{
+			{DataType: zcl.TypeUnsignedInt8, Value: uint64(1)},
+			{DataType: zcl.TypeNull},
+			{DataType: zcl.TypeStringCharacter8, Value: "nested"},
+			{DataType: zcl.TypeArray, Value: zcl.AttributeSlice{
+				DataType: zcl.TypeStructure,
+				Values: []interface{}{
+					[]zcl.AttributeDataTypeValue{{DataType: zcl.TypeBoolean, Value: false}},
+					[]zcl.AttributeDataTypeValue{{DataType: zcl.TypeTimeOfDay, Value: zcl.TimeOfDay{Hours: 1}}},
+				},
+			}},
+		}},
+	}
+
+	for name, expected := range tests {
+		t.Run(name+" stored and retrieved", func(t *testing.T) {
+			s := memory.New()
+
+			Store(s, Key, expected, AttributeDataTypeValueEncoder)
+
+			actual, found := Retrieve(s, Key, AttributeDataTypeValueDecoder)
+			assert.True(t, found)
+			assert.Equal(t, expected, actual)
+		})
+	}
+
+	t.Run("values not matching the data type are not retrieved", func(t *testing.T) {
+		s := memory.New()
+
+		Store(s, Key, zcl.AttributeDataTypeValue{DataType: zcl.TypeBoolean, Value: "true"}, AttributeDataTypeValueEncoder)
+
+		_, found := Retrieve(s, Key, AttributeDataTypeValueDecoder)
+		assert.False(t, found)
+	})
+
+	t.Run("overwriting a value removes stale nested entries", func(t *testing.T) {
+		s := memory.New()
+
+		Store(s, Key, tests["structure"], AttributeDataTypeValueEncoder)
+		Store(s, Key, tests["boolean"], AttributeDataTypeValueEncoder)
+
+		ss, _ := s.SectionIfExists(Key)
+		assert.Empty(t, ss.SectionKeys())
+
+		actual, found := Retrieve(s, Key, AttributeDataTypeValueDecoder)
+		assert.True(t, found)
+		assert.Equal(t, tests["boolean"], actual)
+	})
+}
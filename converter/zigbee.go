@@ -1,6 +1,7 @@
 package converter
 
 import (
+	"fmt"
 	"github.com/shimmeringbee/persistence"
 	"github.com/shimmeringbee/zcl"
 	"github.com/shimmeringbee/zigbee"
@@ -94,3 +95,79 @@ func EndpointDecoder(s persistence.Section, k string) (zigbee.Endpoint, bool) {
 		return 0, false
 	}
 }
+
+func PANIDEncoder(s persistence.Section, k string, v zigbee.PANID) {
+	s.Set(k, int64(v))
+}
+
+func PANIDDecoder(s persistence.Section, k string) (zigbee.PANID, bool) {
+	if ev, found := s.Int(k); found {
+		return zigbee.PANID(ev), true
+	} else {
+		return 0, false
+	}
+}
+
+func ExtendedPANIDEncoder(s persistence.Section, k string, v zigbee.ExtendedPANID) {
+	s.Set(k, fmt.Sprintf("%016x", uint64(v)))
+}
+
+func ExtendedPANIDDecoder(s persistence.Section, k string) (zigbee.ExtendedPANID, bool) {
+	if ev, found := s.String(k); found {
+		if value, err := strconv.ParseUint(ev, 16, 64); err != nil {
+			return 0, false
+		} else {
+			return zigbee.ExtendedPANID(value), true
+		}
+	} else {
+		return 0, false
+	}
+}
+
+func NetworkKeyEncoder(s persistence.Section, k string, v zigbee.NetworkKey) {
+	s.Set(k, v[:])
+}
+
+func NetworkKeyDecoder(s persistence.Section, k string) (zigbee.NetworkKey, bool) {
+	if ev, found := s.Bytes(k); found && len(ev) == len(zigbee.NetworkKey{}) {
+		return zigbee.NetworkKey(ev), true
+	} else {
+		return zigbee.NetworkKey{}, false
+	}
+}
+
+func ChannelEncoder(s persistence.Section, k string, v uint8) {
+	s.Set(k, int64(v))
+}
+
+func ChannelDecoder(s persistence.Section, k string) (uint8, bool) {
+	if ev, found := s.Int(k); found {
+		return uint8(ev), true
+	} else {
+		return 0, false
+	}
+}
+
+func ProfileIDEncoder(s persistence.Section, k string, v zigbee.ProfileID) {
+	s.Set(k, int64(v))
+}
+
+func ProfileIDDecoder(s persistence.Section, k string) (zigbee.ProfileID, bool) {
+	if ev, found := s.Int(k); found {
+		return zigbee.ProfileID(ev), true
+	} else {
+		return 0, false
+	}
+}
+
+func ManufacturerCodeEncoder(s persistence.Section, k string, v zigbee.ManufacturerCode) {
+	s.Set(k, int64(v))
+}
+
+func ManufacturerCodeDecoder(s persistence.Section, k string) (zigbee.ManufacturerCode, bool) {
+	if ev, found := s.Int(k); found {
+		return zigbee.ManufacturerCode(ev), true
+	} else {
+		return 0, false
+	}
+}
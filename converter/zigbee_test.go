@@ -105,3 +105,97 @@ func TestAttributeDataType(t *testing.T) {
 		assert.Equal(t, expected, actual)
 	})
 }
+
+func TestPANID(t *testing.T) {
+	t.Run("stored and retrieved", func(t *testing.T) {
+		s := memory.New()
+
+		expected := zigbee.PANID(0x1a62)
+
+		Store(s, Key, expected, PANIDEncoder)
+
+		actual, found := Retrieve(s, Key, PANIDDecoder)
+		assert.True(t, found)
+		assert.Equal(t, expected, actual)
+	})
+}
+
+func TestExtendedPANID(t *testing.T) {
+	t.Run("stored and retrieved", func(t *testing.T) {
+		s := memory.New()
+
+		expected := zigbee.ExtendedPANID(0xdddddddddddddddd)
+
+		Store(s, Key, expected, ExtendedPANIDEncoder)
+
+		actual, found := Retrieve(s, Key, ExtendedPANIDDecoder)
+		assert.True(t, found)
+		assert.Equal(t, expected, actual)
+	})
+}
+
+func TestNetworkKey(t *testing.T) {
+	t.Run("stored and retrieved", func(t *testing.T) {
+		s := memory.New()
+
+		expected := zigbee.NetworkKey{0x01, 0x03, 0x05, 0x07, 0x09, 0x0b, 0x0d, 0x0f, 0x00, 0x02, 0x04, 0x06, 0x08, 0x0a, 0x0c, 0x0d}
+
+		Store(s, Key, expected, NetworkKeyEncoder)
+
+		actual, found := Retrieve(s, Key, NetworkKeyDecoder)
+		assert.True(t, found)
+		assert.Equal(t, expected, actual)
+	})
+}
+
+func TestChannel(t *testing.T) {
+	t.Run("stored and retrieved", func(t *testing.T) {
+		s := memory.New()
+
+		expected := zigbee.DefaultChannel
+
+		Store(s, Key, expected, ChannelEncoder)
+
+		actual, found := Retrieve(s, Key, ChannelDecoder)
+		assert.True(t, found)
+		assert.Equal(t, expected, actual)
+	})
+}
+
+func TestProfileID(t *testing.T) {
+	t.Run("stored and retrieved", func(t *testing.T) {
+		s := memory.New()
+
+		expected := zigbee.ProfileHomeAutomation
+
+		Store(s, Key, expected, ProfileIDEncoder)
+
+		actual, found := Retrieve(s, Key, ProfileIDDecoder)
+		assert.True(t, found)
+		assert.Equal(t, expected, actual)
+	})
+}
+
+func TestManufacturerCode(t *testing.T) {
+	t.Run("stored and retrieved", func(t *testing.T) {
+		s := memory.New()
+
+		expected := zigbee.ManufacturerCode(0x117c)
+
+		Store(s, Key, expected, ManufacturerCodeEncoder)
+
+		actual, found := Retrieve(s, Key, ManufacturerCodeDecoder)
+		assert.True(t, found)
+		assert.Equal(t, expected, actual)
+	})
+}
+
+func TestNetworkKey_InvalidLength(t *testing.T) {
+	t.Run("keys of the wrong length are not retrieved", func(t *testing.T) {
+		s := memory.New()
+		s.Set(Key, []byte{0x01, 0x02})
+
+		_, found := Retrieve(s, Key, NetworkKeyDecoder)
+		assert.False(t, found)
+	})
+}
@@ -0,0 +1,46 @@
+package persistence
+
+import "bytes"
+
+func Copy(dst Section, src Section) {
+	src.Walk(func(k string, _ ValueType, v any) bool {
+		if b, ok := v.([]byte); ok {
+			v = bytes.Clone(b)
+		}
+
+		dst.Set(k, v)
+		return true
+	})
+
+	for k, s := range src.AllSections() {
+		Copy(dst.Section(k), s)
+	}
+}
+
+func MoveByCopy(src Section, key string, dstParent Section, dstKey string) bool {
+	moving, found := src.SectionIfExists(key)
+	if !found || dstParent.SectionExists(dstKey) {
+		return false
+	}
+
+	if Contains(moving, dstParent) {
+		return false
+	}
+
+	Copy(dstParent.Section(dstKey), moving)
+	return src.SectionDelete(key)
+}
+
+func Contains(s Section, target Section) bool {
+	if s == target {
+		return true
+	}
+
+	for _, ss := range s.AllSections() {
+		if Contains(ss, target) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,54 @@
+package persistence_test
+
+import (
+	"github.com/shimmeringbee/persistence"
+	"github.com/shimmeringbee/persistence/impl/memory"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCopy(t *testing.T) {
+	t.Run("values and sections are deeply copied", func(t *testing.T) {
+		src := memory.New()
+		src.Set("key", "value")
+		src.Set("bytes", []byte{0x01})
+		src.Section("a", "b").Set("key", uint64(2))
+
+		dst := memory.New()
+		persistence.Copy(dst, src)
+
+		v, _ := dst.String("key")
+		assert.Equal(t, "value", v)
+
+		u, _ := dst.Section("a", "b").UInt("key")
+		assert.Equal(t, uint64(2), u)
+
+		b, _ := src.Bytes("bytes")
+		b[0] = 0xff
+
+		b, _ = dst.Bytes("bytes")
+		assert.Equal(t, []byte{0x01}, b)
+	})
+}
+
+func TestMoveByCopy(t *testing.T) {
+	t.Run("section is copied to destination and removed from source", func(t *testing.T) {
+		src := memory.New()
+		src.Section("a").Set("key", "value")
+
+		dst := memory.New()
+
+		assert.True(t, persistence.MoveByCopy(src, "a", dst, "b"))
+		assert.False(t, src.SectionExists("a"))
+
+		v, _ := dst.Section("b").String("key")
+		assert.Equal(t, "value", v)
+	})
+
+	t.Run("fails if destination is within the moved section", func(t *testing.T) {
+		src := memory.New()
+
+		assert.False(t, persistence.MoveByCopy(src, "a", src.Section("a", "b"), "c"))
+		assert.True(t, src.SectionExists("a"))
+	})
+}
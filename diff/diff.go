@@ -0,0 +1,234 @@
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/shimmeringbee/persistence"
+	"slices"
+	"strings"
+)
+
+type Kind uint8
+
+const (
+	Added          Kind = 0
+	Removed        Kind = 1
+	Changed        Kind = 2
+	TypeChanged    Kind = 3
+	SectionAdded   Kind = 4
+	SectionRemoved Kind = 5
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Added:
+		return "+"
+	case Removed:
+		return "-"
+	case Changed:
+		return "~"
+	case TypeChanged:
+		return "!"
+	case SectionAdded:
+		return "+/"
+	case SectionRemoved:
+		return "-/"
+	default:
+		return "?"
+	}
+}
+
+type Change struct {
+	Path string
+	Kind Kind
+
+	OldType  persistence.ValueType
+	OldValue any
+	NewType  persistence.ValueType
+	NewValue any
+}
+
+func (c Change) String() string {
+	switch c.Kind {
+	case Added:
+		return fmt.Sprintf("%s %s: %v", c.Kind, c.Path, c.NewValue)
+	case Removed:
+		return fmt.Sprintf("%s %s: %v", c.Kind, c.Path, c.OldValue)
+	case Changed, TypeChanged:
+		return fmt.Sprintf("%s %s: %v -> %v", c.Kind, c.Path, c.OldValue, c.NewValue)
+	default:
+		return fmt.Sprintf("%s %s", c.Kind, c.Path)
+	}
+}
+
+type Diff []Change
+
+func (d Diff) String() string {
+	var sb strings.Builder
+
+	for _, c := range d {
+		sb.WriteString(c.String())
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+func Compute(from persistence.Section, to persistence.Section) Diff {
+	var d Diff
+	compute(&d, "", from, to)
+	return d
+}
+
+func compute(d *Diff, base string, from persistence.Section, to persistence.Section) {
+	for _, k := range union(from.Keys(), to.Keys()) {
+		oldValue, oldType, inFrom := persistence.Get(from, k)
+		newValue, newType, inTo := persistence.Get(to, k)
+
+		c := Change{Path: base + k, OldType: oldType, OldValue: oldValue, NewType: newType, NewValue: newValue}
+
+		switch {
+		case inFrom && !inTo:
+			c.Kind = Removed
+		case !inFrom && inTo:
+			c.Kind = Added
+		case oldType != newType:
+			c.Kind = TypeChanged
+		case !equal(oldValue, newValue):
+			c.Kind = Changed
+		default:
+			continue
+		}
+
+		*d = append(*d, c)
+	}
+
+	for _, k := range union(from.SectionKeys(), to.SectionKeys()) {
+		inFrom := from.SectionExists(k)
+		inTo := to.SectionExists(k)
+		path := base + k
+
+		switch {
+		case inFrom && inTo:
+			compute(d, path+persistence.PathSeparator, from.Section(k), to.Section(k))
+		case inFrom:
+			removed(d, path+persistence.PathSeparator, from.Section(k))
+			*d = append(*d, Change{Path: path, Kind: SectionRemoved})
+		case inTo:
+			*d = append(*d, Change{Path: path, Kind: SectionAdded})
+			added(d, path+persistence.PathSeparator, to.Section(k))
+		}
+	}
+}
+
+func added(d *Diff, base string, s persistence.Section) {
+	s.Walk(func(k string, vt persistence.ValueType, v any) bool {
+		*d = append(*d, Change{Path: base + k, Kind: Added, OldType: persistence.None, NewType: vt, NewValue: v})
+		return true
+	})
+
+	for k, ss := range s.AllSections() {
+		*d = append(*d, Change{Path: base + k, Kind: SectionAdded})
+		added(d, base+k+persistence.PathSeparator, ss)
+	}
+}
+
+func removed(d *Diff, base string, s persistence.Section) {
+	s.Walk(func(k string, vt persistence.ValueType, v any) bool {
+		*d = append(*d, Change{Path: base + k, Kind: Removed, OldType: vt, OldValue: v, NewType: persistence.None})
+		return true
+	})
+
+	for k, ss := range s.AllSections() {
+		removed(d, base+k+persistence.PathSeparator, ss)
+		*d = append(*d, Change{Path: base + k, Kind: SectionRemoved})
+	}
+}
+
+func union(a []string, b []string) []string {
+	keys := slices.Concat(a, b)
+	slices.Sort(keys)
+	return slices.Compact(keys)
+}
+
+func equal(a any, b any) bool {
+	if ab, ok := a.([]byte); ok {
+		if bb, ok := b.([]byte); ok {
+			return bytes.Equal(ab, bb)
+		}
+
+		return false
+	}
+
+	return a == b
+}
+
+type Conflict struct {
+	Change Change
+
+	ActualType  persistence.ValueType
+	ActualValue any
+}
+
+func (c Conflict) String() string {
+	return fmt.Sprintf("conflict %s (actual: %v)", c.Change, c.ActualValue)
+}
+
+func Apply(s persistence.Section, d Diff) []Conflict {
+	var conflicts []Conflict
+
+	for _, c := range d {
+		switch c.Kind {
+		case SectionAdded:
+			_ = s.Section(strings.Split(c.Path, persistence.PathSeparator)...)
+		case SectionRemoved:
+			sections := strings.Split(c.Path, persistence.PathSeparator)
+
+			if parent, found := lookupSection(s, sections[:len(sections)-1]); found {
+				key := sections[len(sections)-1]
+
+				if ss, found := parent.SectionIfExists(key); found && (len(ss.Keys()) > 0 || len(ss.SectionKeys()) > 0) {
+					conflicts = append(conflicts, Conflict{Change: c, ActualType: persistence.None})
+					continue
+				}
+
+				parent.SectionDelete(key)
+			}
+		default:
+			actual, actualType, found := persistence.GetPath(s, c.Path)
+
+			if found && actualType == c.NewType && equal(actual, c.NewValue) {
+				continue
+			}
+
+			if c.Kind == Removed && !found {
+				continue
+			}
+
+			if found != (c.Kind != Added) || (found && (actualType != c.OldType || !equal(actual, c.OldValue))) {
+				conflicts = append(conflicts, Conflict{Change: c, ActualType: actualType, ActualValue: actual})
+				continue
+			}
+
+			if c.Kind == Removed {
+				persistence.DeletePath(s, c.Path)
+			} else if err := persistence.SetPath(s, c.Path, c.NewValue); err != nil {
+				conflicts = append(conflicts, Conflict{Change: c, ActualType: actualType, ActualValue: actual})
+			}
+		}
+	}
+
+	return conflicts
+}
+
+func Merge(dst persistence.Section, base persistence.Section, theirs persistence.Section) []Conflict {
+	return Apply(dst, Compute(base, theirs))
+}
+
+func lookupSection(s persistence.Section, sections []string) (persistence.Section, bool) {
+	if len(sections) == 0 {
+		return s, true
+	}
+
+	return s.SectionIfExists(sections...)
+}
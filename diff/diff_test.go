@@ -0,0 +1,195 @@
+package diff
+
+import (
+	"github.com/shimmeringbee/persistence"
+	"github.com/shimmeringbee/persistence/impl/memory"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCompute(t *testing.T) {
+	t.Run("identical trees have no differences", func(t *testing.T) {
+		a := memory.New()
+		a.Set("key", "value")
+		a.Section("s").Set("bytes", []byte{0x01})
+
+		b := memory.New()
+		b.Set("key", "value")
+		b.Section("s").Set("bytes", []byte{0x01})
+
+		assert.Empty(t, Compute(a, b))
+	})
+
+	t.Run("added, removed, changed and type changed keys are reported", func(t *testing.T) {
+		a := memory.New()
+		a.Set("changed", 1)
+		a.Set("removed", "gone")
+		a.Set("retyped", 1)
+
+		b := memory.New()
+		b.Set("added", true)
+		b.Set("changed", 2)
+		b.Set("retyped", uint(1))
+
+		expected := Diff{
+			{Path: "added", Kind: Added, OldType: persistence.None, NewType: persistence.Bool, NewValue: true},
+			{Path: "changed", Kind: Changed, OldType: persistence.Int, OldValue: int64(1), NewType: persistence.Int, NewValue: int64(2)},
+			{Path: "removed", Kind: Removed, OldType: persistence.String, OldValue: "gone", NewType: persistence.None},
+			{Path: "retyped", Kind: TypeChanged, OldType: persistence.Int, OldValue: int64(1), NewType: persistence.UnsignedInt, NewValue: uint64(1)},
+		}
+
+		assert.Equal(t, expected, Compute(a, b))
+	})
+
+	t.Run("section additions and removals include their contents", func(t *testing.T) {
+		a := memory.New()
+		a.Section("old", "inner").Set("key", 1)
+		a.Section("common").Set("key", 1)
+
+		b := memory.New()
+		b.Section("new", "inner").Set("key", "value")
+		b.Section("common").Set("key", 2)
+
+		expected := Diff{
+			{Path: "common/key", Kind: Changed, OldType: persistence.Int, OldValue: int64(1), NewType: persistence.Int, NewValue: int64(2)},
+			{Path: "new", Kind: SectionAdded},
+			{Path: "new/inner", Kind: SectionAdded},
+			{Path: "new/inner/key", Kind: Added, OldType: persistence.None, NewType: persistence.String, NewValue: "value"},
+			{Path: "old/inner/key", Kind: Removed, OldType: persistence.Int, OldValue: int64(1), NewType: persistence.None},
+			{Path: "old/inner", Kind: SectionRemoved},
+			{Path: "old", Kind: SectionRemoved},
+		}
+
+		assert.Equal(t, expected, Compute(a, b))
+	})
+
+	t.Run("diff can be rendered as text", func(t *testing.T) {
+		a := memory.New()
+		a.Set("key", 1)
+
+		b := memory.New()
+		b.Set("key", 2)
+		b.Section("s")
+
+		assert.Equal(t, "~ key: 1 -> 2\n+/ s\n", Compute(a, b).String())
+	})
+}
+
+func TestApply(t *testing.T) {
+	t.Run("applying a diff makes the trees identical", func(t *testing.T) {
+		a := memory.New()
+		a.Set("changed", 1)
+		a.Set("removed", "gone")
+		a.Section("old").Set("key", 1)
+
+		b := memory.New()
+		b.Set("changed", "now a string")
+		b.Section("new", "inner").Set("key", []byte{0x01})
+
+		conflicts := Apply(a, Compute(a, b))
+		assert.Empty(t, conflicts)
+		assert.Empty(t, Compute(a, b))
+	})
+
+	t.Run("changes whose precondition do not hold are reported as conflicts", func(t *testing.T) {
+		base := memory.New()
+		base.Set("key", 1)
+
+		theirs := memory.New()
+		theirs.Set("key", 2)
+
+		ours := memory.New()
+		ours.Set("key", 3)
+
+		conflicts := Apply(ours, Compute(base, theirs))
+		assert.Len(t, conflicts, 1)
+		assert.Equal(t, "key", conflicts[0].Change.Path)
+		assert.Equal(t, int64(3), conflicts[0].ActualValue)
+
+		v, _ := ours.Int("key")
+		assert.Equal(t, int64(3), v)
+	})
+}
+
+func TestMerge(t *testing.T) {
+	t.Run("non overlapping changes are merged", func(t *testing.T) {
+		base := memory.New()
+		base.Set("a", 1)
+		base.Set("b", 1)
+
+		ours := memory.New()
+		ours.Set("a", 2)
+		ours.Set("b", 1)
+
+		theirs := memory.New()
+		theirs.Set("a", 1)
+		theirs.Set("b", 2)
+		theirs.Section("s").Set("c", 3)
+
+		assert.Empty(t, Merge(ours, base, theirs))
+
+		a, _ := ours.Int("a")
+		assert.Equal(t, int64(2), a)
+
+		b, _ := ours.Int("b")
+		assert.Equal(t, int64(2), b)
+
+		c, _ := ours.Section("s").Int("c")
+		assert.Equal(t, int64(3), c)
+	})
+
+	t.Run("identical changes on both sides do not conflict", func(t *testing.T) {
+		base := memory.New()
+		base.Set("a", 1)
+
+		ours := memory.New()
+		ours.Set("a", 2)
+
+		theirs := memory.New()
+		theirs.Set("a", 2)
+
+		assert.Empty(t, Merge(ours, base, theirs))
+	})
+
+	t.Run("removed sections are merged when unchanged locally", func(t *testing.T) {
+		base := memory.New()
+		base.Section("s", "inner").Set("a", 1)
+
+		ours := memory.New()
+		persistence.Copy(ours, base)
+
+		theirs := memory.New()
+
+		assert.Empty(t, Merge(ours, base, theirs))
+		assert.False(t, ours.SectionExists("s"))
+	})
+
+	t.Run("removed sections with local changes are kept and reported as conflicts", func(t *testing.T) {
+		base := memory.New()
+		base.Section("s").Set("a", 1)
+		base.Section("t").Set("a", 1)
+
+		ours := memory.New()
+		ours.Section("s").Set("a", 1)
+		ours.Section("s").Set("local", true)
+		ours.Section("t").Set("a", 2)
+
+		theirs := memory.New()
+
+		conflicts := Merge(ours, base, theirs)
+
+		var paths []string
+		for _, c := range conflicts {
+			paths = append(paths, c.Change.Path)
+		}
+
+		assert.Equal(t, []string{"s", "t/a", "t"}, paths)
+
+		local, found := ours.Section("s").Bool("local")
+		assert.True(t, found)
+		assert.True(t, local)
+
+		a, _ := ours.Section("t").Int("a")
+		assert.Equal(t, int64(2), a)
+	})
+}
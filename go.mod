@@ -1,11 +1,13 @@
 module github.com/shimmeringbee/persistence
 
-go 1.22.0
+go 1.23.0
 
 require (
+	github.com/fxamacker/cbor/v2 v2.9.4
 	github.com/shimmeringbee/zcl v0.0.0-20240509210644-817a66d91348
 	github.com/shimmeringbee/zigbee v0.0.0-20201027194100-4e53cafc0f7a
 	github.com/stretchr/testify v1.9.0
+	github.com/vmihailenco/msgpack/v5 v5.4.1
 )
 
 require (
@@ -13,5 +15,7 @@ require (
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/shimmeringbee/bytecodec v0.0.0-20201107142444-94bb5c0baaee // indirect
 	github.com/stretchr/objx v0.5.2 // indirect
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,46 @@
+package archive
+
+import (
+	"archive/zip"
+	"errors"
+	"github.com/shimmeringbee/persistence"
+	"github.com/shimmeringbee/persistence/impl/file"
+	"github.com/shimmeringbee/persistence/impl/memory"
+	"github.com/shimmeringbee/persistence/impl/readonly"
+	"io"
+	"io/fs"
+)
+
+func New(fsys fs.FS, opts ...file.Option) (persistence.Section, error) {
+	return NewSub(fsys, ".", opts...)
+}
+
+func NewSub(fsys fs.FS, dir string, opts ...file.Option) (persistence.Section, error) {
+	m := memory.New()
+
+	if err := file.LoadFS(fsys, dir, m, opts...); err != nil {
+		return nil, err
+	}
+
+	return readonly.New(m), nil
+}
+
+func NewZip(r io.ReaderAt, size int64, opts ...file.Option) (persistence.Section, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(zr, opts...)
+}
+
+func NewTar(r io.Reader, opts ...file.Option) (persistence.Section, error) {
+	fsys, err := readTar(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(fsys, opts...)
+}
+
+var ErrInvalidPath = errors.New("invalid path in archive")
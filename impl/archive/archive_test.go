@@ -0,0 +1,107 @@
+package archive
+
+import (
+	"bytes"
+	"embed"
+	"github.com/shimmeringbee/persistence"
+	"github.com/shimmeringbee/persistence/impl/file"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+//go:embed testdata/quirks
+var quirks embed.FS
+
+func populate(t *testing.T) persistence.Section {
+	f := file.New(filepath.Join(t.TempDir(), "store"), file.SidecarThreshold(4))
+	f.Set("key", "value")
+	f.Section("a", "b").Set("bytes", []byte("large value"))
+	f.Section("empty")
+	f.(persistence.Syncer).Sync()
+
+	return f
+}
+
+func assertPopulated(t *testing.T, s persistence.Section) {
+	v, found := s.String("key")
+	assert.True(t, found)
+	assert.Equal(t, "value", v)
+
+	b, found := s.Section("a", "b").Bytes("bytes")
+	assert.True(t, found)
+	assert.Equal(t, []byte("large value"), b)
+
+	assert.True(t, s.SectionExists("empty"))
+}
+
+func TestNew(t *testing.T) {
+	t.Run("tree is served from a directory", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "store")
+
+		f := file.New(dir)
+		f.Set("key", "value")
+		f.(persistence.Syncer).Sync()
+
+		s, err := New(os.DirFS(dir))
+		assert.NoError(t, err)
+
+		v, _ := s.String("key")
+		assert.Equal(t, "value", v)
+	})
+
+	t.Run("tree is served from an embedded filesystem", func(t *testing.T) {
+		s, err := NewSub(quirks, "testdata/quirks")
+		assert.NoError(t, err)
+
+		v, _ := s.Int("version")
+		assert.Equal(t, int64(1), v)
+
+		m, _ := s.Section("devices", "0x00124b").String("manufacturer")
+		assert.Equal(t, "Example", m)
+	})
+
+	t.Run("sections are read only", func(t *testing.T) {
+		s, err := NewSub(quirks, "testdata/quirks")
+		assert.NoError(t, err)
+
+		assert.Panics(t, func() { s.Set("version", 2) })
+		assert.Panics(t, func() { s.SectionDelete("devices") })
+	})
+}
+
+func TestNewTar(t *testing.T) {
+	t.Run("tree is served from a tar archive", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		assert.NoError(t, file.Archive(populate(t), buf, file.Tar))
+
+		s, err := NewTar(buf)
+		assert.NoError(t, err)
+
+		assertPopulated(t, s)
+	})
+
+	t.Run("tar filesystem conforms to fs.FS", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		assert.NoError(t, file.Archive(populate(t), buf, file.Tar))
+
+		fsys, err := readTar(buf)
+		assert.NoError(t, err)
+
+		assert.NoError(t, fstest.TestFS(fsys, "data.json", "a/b/data.json", "empty"))
+	})
+}
+
+func TestNewZip(t *testing.T) {
+	t.Run("tree is served from a zip archive", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		assert.NoError(t, file.Archive(populate(t), buf, file.Zip))
+
+		s, err := NewZip(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		assert.NoError(t, err)
+
+		assertPopulated(t, s)
+	})
+}
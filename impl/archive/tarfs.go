@@ -0,0 +1,174 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+type tarFS struct {
+	entries map[string]*tarEntry
+}
+
+type tarEntry struct {
+	name     string
+	dir      bool
+	data     []byte
+	modTime  time.Time
+	children []string
+}
+
+func (e *tarEntry) Name() string {
+	return path.Base(e.name)
+}
+
+func (e *tarEntry) Size() int64 {
+	return int64(len(e.data))
+}
+
+func (e *tarEntry) Mode() fs.FileMode {
+	if e.dir {
+		return fs.ModeDir | 0500
+	}
+
+	return 0400
+}
+
+func (e *tarEntry) ModTime() time.Time {
+	return e.modTime
+}
+
+func (e *tarEntry) IsDir() bool {
+	return e.dir
+}
+
+func (e *tarEntry) Sys() any {
+	return nil
+}
+
+func readTar(r io.Reader) (*tarFS, error) {
+	t := &tarFS{entries: map[string]*tarEntry{".": {name: ".", dir: true}}}
+	tr := tar.NewReader(r)
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		name := path.Clean(strings.TrimSuffix(hdr.Name, "/"))
+		if !fs.ValidPath(name) || name == "." {
+			return nil, &fs.PathError{Op: "read", Path: hdr.Name, Err: ErrInvalidPath}
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			t.add(name, true, nil, hdr.ModTime)
+		case tar.TypeReg:
+			data, err := io.ReadAll(tr)
+			if err != nil {
+				return nil, err
+			}
+
+			t.add(name, false, data, hdr.ModTime)
+		}
+	}
+
+	for _, e := range t.entries {
+		slices.Sort(e.children)
+	}
+
+	return t, nil
+}
+
+func (t *tarFS) add(name string, dir bool, data []byte, modTime time.Time) {
+	if e, ok := t.entries[name]; ok {
+		e.dir, e.data, e.modTime = dir, data, modTime
+		return
+	}
+
+	t.entries[name] = &tarEntry{name: name, dir: dir, data: data, modTime: modTime}
+
+	parent := path.Dir(name)
+	if _, ok := t.entries[parent]; !ok {
+		t.add(parent, true, nil, modTime)
+	}
+
+	t.entries[parent].children = append(t.entries[parent].children, name)
+}
+
+func (t *tarFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	e, ok := t.entries[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	if e.dir {
+		return &tarDir{fs: t, entry: e}, nil
+	}
+
+	return &tarFile{entry: e, Reader: bytes.NewReader(e.data)}, nil
+}
+
+type tarFile struct {
+	*bytes.Reader
+	entry *tarEntry
+}
+
+func (f *tarFile) Stat() (fs.FileInfo, error) {
+	return f.entry, nil
+}
+
+func (f *tarFile) Close() error {
+	return nil
+}
+
+type tarDir struct {
+	fs     *tarFS
+	entry  *tarEntry
+	offset int
+}
+
+func (d *tarDir) Stat() (fs.FileInfo, error) {
+	return d.entry, nil
+}
+
+func (d *tarDir) Read(_ []byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.entry.name, Err: fs.ErrInvalid}
+}
+
+func (d *tarDir) Close() error {
+	return nil
+}
+
+func (d *tarDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	remaining := d.entry.children[d.offset:]
+
+	if n > 0 && len(remaining) == 0 {
+		return nil, io.EOF
+	}
+
+	if n > 0 && n < len(remaining) {
+		remaining = remaining[:n]
+	}
+
+	entries := make([]fs.DirEntry, 0, len(remaining))
+
+	for _, name := range remaining {
+		entries = append(entries, fs.FileInfoToDirEntry(d.fs.entries[name]))
+	}
+
+	d.offset += len(remaining)
+	return entries, nil
+}
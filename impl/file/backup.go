@@ -0,0 +1,393 @@
+package file
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/shimmeringbee/persistence"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const generationFormat = "20060102T150405.000000000Z"
+
+var ErrNotFileSection = errors.New("section is not backed by the file implementation")
+var ErrDestinationNotEmpty = errors.New("restore destination is not empty")
+var ErrInvalidArchivePath = errors.New("invalid path in archive")
+
+type Generation struct {
+	Name string
+	Time time.Time
+	Path string
+}
+
+func Generations(fsys FS, backupDir string) ([]Generation, error) {
+	entries, err := fsys.ReadDir(backupDir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	var generations []Generation
+
+	for _, ent := range entries {
+		if !ent.IsDir() {
+			continue
+		}
+
+		t, err := time.Parse(generationFormat, ent.Name())
+		if err != nil {
+			continue
+		}
+
+		generations = append(generations, Generation{Name: ent.Name(), Time: t, Path: filepath.Join(backupDir, ent.Name())})
+	}
+
+	slices.SortFunc(generations, func(a, b Generation) int {
+		return a.Time.Compare(b.Time)
+	})
+
+	return generations, nil
+}
+
+func (f *file) snapshot() error {
+	backupDir := f.store.opts.backupDir
+	fsys := f.store.opts.fs
+
+	moveLock.Lock()
+	defer moveLock.Unlock()
+
+	dst := filepath.Join(backupDir, time.Now().UTC().Format(generationFormat))
+
+	if err := copyTree(fsys, f.dir, dst, true); err != nil {
+		return err
+	}
+
+	generations, err := Generations(fsys, backupDir)
+	if err != nil {
+		return err
+	}
+
+	for len(generations) > f.store.opts.backupGenerations {
+		if err := fsys.RemoveAll(generations[0].Path); err != nil {
+			return err
+		}
+
+		generations = generations[1:]
+	}
+
+	return nil
+}
+
+func RestoreGeneration(fsys FS, backupDir string, name string, dst string) error {
+	if err := checkEmpty(fsys, dst); err != nil {
+		return err
+	}
+
+	return copyTree(fsys, filepath.Join(backupDir, name), dst, false)
+}
+
+func isTemporary(name string) bool {
+	return strings.HasSuffix(name, ".tmp")
+}
+
+func copyTree(fsys FS, src string, dst string, link bool) error {
+	if _, err := fsys.Stat(src); err != nil {
+		return err
+	}
+
+	if err := fsys.MkdirAll(dst, 0700); err != nil {
+		return err
+	}
+
+	return walkDir(fsys, src, func(p string, d fs.DirEntry) error {
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dst, rel)
+
+		if d.IsDir() {
+			return fsys.MkdirAll(target, 0700)
+		}
+
+		if !d.Type().IsRegular() || isTemporary(d.Name()) {
+			return nil
+		}
+
+		if link {
+			if err := fsys.Link(p, target); err == nil {
+				return nil
+			}
+		}
+
+		return copyFile(fsys, p, target)
+	})
+}
+
+func copyFile(fsys FS, src string, dst string) error {
+	data, err := fsys.ReadFile(src)
+	if err != nil {
+		return err
+	}
+
+	return writeFrom(fsys, dst, bytes.NewReader(data))
+}
+
+func writeFrom(fsys FS, dst string, r io.Reader) error {
+	w, err := fsys.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(w, r); err != nil {
+		_ = w.Close()
+		return err
+	}
+
+	return w.Close()
+}
+
+func checkEmpty(fsys FS, dir string) error {
+	entries, err := fsys.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+
+		return err
+	}
+
+	if len(entries) > 0 {
+		return fmt.Errorf("%s: %w", dir, ErrDestinationNotEmpty)
+	}
+
+	return nil
+}
+
+type ArchiveFormat uint8
+
+const (
+	Tar ArchiveFormat = 0
+	Zip ArchiveFormat = 1
+)
+
+func Archive(s persistence.Section, w io.Writer, format ArchiveFormat) error {
+	f, ok := s.(*file)
+	if !ok {
+		return ErrNotFileSection
+	}
+
+	f.Sync()
+
+	moveLock.Lock()
+	defer moveLock.Unlock()
+
+	switch format {
+	case Tar:
+		return archiveTar(f.store.opts.fs, f.dir, w)
+	case Zip:
+		return archiveZip(f.store.opts.fs, f.dir, w)
+	default:
+		return fmt.Errorf("unknown archive format: %d", format)
+	}
+}
+
+func walkArchivable(fsys FS, dir string, fn func(name string, p string, d fs.DirEntry) error) error {
+	return walkDir(fsys, dir, func(p string, d fs.DirEntry) error {
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+
+		if !d.IsDir() && (!d.Type().IsRegular() || isTemporary(d.Name())) {
+			return nil
+		}
+
+		return fn(filepath.ToSlash(rel), p, d)
+	})
+}
+
+func archiveTar(fsys FS, dir string, w io.Writer) error {
+	tw := tar.NewWriter(w)
+
+	err := walkArchivable(fsys, dir, func(name string, p string, d fs.DirEntry) error {
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+
+		hdr.Name = name
+		if d.IsDir() {
+			hdr.Name += "/"
+		}
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		return copyInto(fsys, tw, p)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return tw.Close()
+}
+
+func archiveZip(fsys FS, dir string, w io.Writer) error {
+	zw := zip.NewWriter(w)
+
+	err := walkArchivable(fsys, dir, func(name string, p string, d fs.DirEntry) error {
+		if d.IsDir() {
+			_, err := zw.Create(name + "/")
+			return err
+		}
+
+		fw, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+
+		return copyInto(fsys, fw, p)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+func copyInto(fsys FS, w io.Writer, p string) error {
+	data, err := fsys.ReadFile(p)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+func RestoreArchive(fsys FS, r io.ReaderAt, size int64, format ArchiveFormat, dst string) error {
+	if err := checkEmpty(fsys, dst); err != nil {
+		return err
+	}
+
+	if err := fsys.MkdirAll(dst, 0700); err != nil {
+		return err
+	}
+
+	switch format {
+	case Tar:
+		return restoreTar(fsys, io.NewSectionReader(r, 0, size), dst)
+	case Zip:
+		return restoreZip(fsys, r, size, dst)
+	default:
+		return fmt.Errorf("unknown archive format: %d", format)
+	}
+}
+
+func archiveTarget(dst string, name string) (string, error) {
+	clean := path.Clean(strings.TrimSuffix(name, "/"))
+
+	if clean == "." || path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("%q: %w", name, ErrInvalidArchivePath)
+	}
+
+	return filepath.Join(dst, filepath.FromSlash(clean)), nil
+}
+
+func restoreEntry(fsys FS, dst string, name string, isDir bool, r io.Reader) error {
+	target, err := archiveTarget(dst, name)
+	if err != nil {
+		return err
+	}
+
+	if isDir {
+		return fsys.MkdirAll(target, 0700)
+	}
+
+	if err := fsys.MkdirAll(filepath.Dir(target), 0700); err != nil {
+		return err
+	}
+
+	return writeFrom(fsys, target, r)
+}
+
+func restoreTar(fsys FS, r io.Reader, dst string) error {
+	tr := tar.NewReader(r)
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = restoreEntry(fsys, dst, hdr.Name, true, nil)
+		case tar.TypeReg:
+			err = restoreEntry(fsys, dst, hdr.Name, false, tr)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func restoreZip(fsys FS, r io.ReaderAt, size int64, dst string) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return err
+	}
+
+	for _, zf := range zr.File {
+		if strings.HasSuffix(zf.Name, "/") {
+			if err := restoreEntry(fsys, dst, zf.Name, true, nil); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		fr, err := zf.Open()
+		if err != nil {
+			return err
+		}
+
+		err = restoreEntry(fsys, dst, zf.Name, false, fr)
+		_ = fr.Close()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
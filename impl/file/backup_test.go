@@ -0,0 +1,106 @@
+package file
+
+import (
+	"archive/tar"
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBackups(t *testing.T) {
+	t.Run("generations are kept on sync and pruned", func(t *testing.T) {
+		dir := t.TempDir()
+		backupDir := filepath.Join(t.TempDir(), "backups")
+
+		f := New(filepath.Join(dir, "store"), Backups(backupDir, 2)).(*file)
+
+		for _, v := range []string{"one", "two", "three"} {
+			f.Section("device").Set("key", v)
+			f.Sync()
+		}
+
+		generations, err := Generations(OS, backupDir)
+		assert.NoError(t, err)
+		assert.Len(t, generations, 2)
+
+		restored := filepath.Join(dir, "restored")
+		assert.NoError(t, RestoreGeneration(OS, backupDir, generations[0].Name, restored))
+
+		v, _ := New(restored).Section("device").String("key")
+		assert.Equal(t, "two", v)
+	})
+
+	t.Run("generations are not affected by later writes", func(t *testing.T) {
+		dir := t.TempDir()
+		backupDir := filepath.Join(t.TempDir(), "backups")
+
+		f := New(filepath.Join(dir, "store"), Backups(backupDir, 5)).(*file)
+		f.Set("key", "before")
+		f.Sync()
+
+		f.Set("key", "after")
+		f.sync(true)
+
+		generations, err := Generations(OS, backupDir)
+		assert.NoError(t, err)
+		assert.Len(t, generations, 1)
+
+		v, _ := New(generations[0].Path, ReadOnly()).String("key")
+		assert.Equal(t, "before", v)
+	})
+
+	t.Run("restore refuses a non empty destination", func(t *testing.T) {
+		backupDir := t.TempDir()
+		assert.NoError(t, os.Mkdir(filepath.Join(backupDir, "gen"), 0700))
+
+		dst := t.TempDir()
+		assert.NoError(t, os.WriteFile(filepath.Join(dst, "existing"), nil, 0600))
+
+		assert.ErrorIs(t, RestoreGeneration(OS, backupDir, "gen", dst), ErrDestinationNotEmpty)
+	})
+}
+
+func TestArchive(t *testing.T) {
+	for name, format := range map[string]ArchiveFormat{"Tar": Tar, "Zip": Zip} {
+		t.Run(name+" archive can be restored", func(t *testing.T) {
+			dir := t.TempDir()
+
+			f := New(filepath.Join(dir, "store"), SidecarThreshold(4)).(*file)
+			f.Set("key", "value")
+			f.Section("a", "b").Set("bytes", []byte("large value"))
+			f.Section("empty")
+
+			buf := &bytes.Buffer{}
+			assert.NoError(t, Archive(f, buf, format))
+
+			restored := filepath.Join(dir, "restored")
+			assert.NoError(t, RestoreArchive(OS, bytes.NewReader(buf.Bytes()), int64(buf.Len()), format, restored))
+
+			r := New(restored, SidecarThreshold(4))
+
+			v, _ := r.String("key")
+			assert.Equal(t, "value", v)
+
+			b, _ := r.Section("a", "b").Bytes("bytes")
+			assert.Equal(t, []byte("large value"), b)
+
+			assert.True(t, r.SectionExists("empty"))
+		})
+	}
+
+	t.Run("archive entries escaping the destination are rejected", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		tw := tar.NewWriter(buf)
+		assert.NoError(t, tw.WriteHeader(&tar.Header{Name: "../escape", Typeflag: tar.TypeReg, Size: 0, Mode: 0600}))
+		assert.NoError(t, tw.Close())
+
+		err := RestoreArchive(OS, bytes.NewReader(buf.Bytes()), int64(buf.Len()), Tar, t.TempDir())
+		assert.ErrorIs(t, err, ErrInvalidArchivePath)
+	})
+
+	t.Run("only file sections can be archived", func(t *testing.T) {
+		assert.ErrorIs(t, Archive(New(t.TempDir(), ReadOnly()), &bytes.Buffer{}, Tar), ErrNotFileSection)
+	})
+}
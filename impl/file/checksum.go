@@ -0,0 +1,140 @@
+package file
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/fs"
+	"strings"
+)
+
+type ChecksumAlgorithm string
+
+const (
+	NoChecksum ChecksumAlgorithm = ""
+	CRC32C     ChecksumAlgorithm = "crc32c"
+	SHA256     ChecksumAlgorithm = "sha256"
+)
+
+const (
+	checksumSuffix = ".sum"
+	previousSuffix = ".prev"
+)
+
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+func (a ChecksumAlgorithm) sum(b []byte) (string, error) {
+	switch a {
+	case CRC32C:
+		return fmt.Sprintf("%08x", crc32.Checksum(b, crc32cTable)), nil
+	case SHA256:
+		s := sha256.Sum256(b)
+		return hex.EncodeToString(s[:]), nil
+	default:
+		return "", fmt.Errorf("unknown checksum algorithm: %q", string(a))
+	}
+}
+
+func writeChecksum(fsys FS, path string, a ChecksumAlgorithm, b []byte) error {
+	if a == NoChecksum {
+		if err := fsys.Remove(path + checksumSuffix); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+
+		return nil
+	}
+
+	sum, err := a.sum(b)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(fsys, path+checksumSuffix, []byte(fmt.Sprintf("%s:%s\n", a, sum)))
+}
+
+type readFunc func(name string) ([]byte, error)
+
+func verifyChecksum(read readFunc, path string, b []byte) error {
+	recorded, err := read(path + checksumSuffix)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+
+		return err
+	}
+
+	algorithm, expected, found := strings.Cut(strings.TrimSpace(string(recorded)), ":")
+	if !found {
+		return fmt.Errorf("%s: malformed checksum: %w", path, ErrChecksumMismatch)
+	}
+
+	actual, err := ChecksumAlgorithm(algorithm).sum(b)
+	if err != nil {
+		return fmt.Errorf("%s: %w: %w", path, err, ErrChecksumMismatch)
+	}
+
+	if actual != expected {
+		return fmt.Errorf("%s: expected %s got %s: %w", path, expected, actual, ErrChecksumMismatch)
+	}
+
+	return nil
+}
+
+func readVerified(read readFunc, path string) ([]byte, error) {
+	b, err := read(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifyChecksum(read, path, b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+func rotatePrevious(fsys FS, path string) error {
+	suffixes := []string{"", checksumSuffix}
+
+	for _, suffix := range suffixes {
+		if err := fsys.Remove(path + previousSuffix + suffix); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	for _, suffix := range suffixes {
+		if err := copyPrevious(fsys, path+suffix, path+previousSuffix+suffix); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func copyPrevious(fsys FS, src string, dst string) error {
+	if err := fsys.Link(src, dst); err == nil || errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	b, err := fsys.ReadFile(src)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(fsys, dst, b)
+}
+
+func removeWithGenerations(fsys FS, path string) error {
+	for _, suffix := range []string{"", checksumSuffix, previousSuffix, previousSuffix + checksumSuffix} {
+		if err := fsys.Remove(path + suffix); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	return nil
+}
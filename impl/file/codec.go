@@ -0,0 +1,175 @@
+package file
+
+import (
+	"encoding/json"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/shimmeringbee/persistence"
+	"github.com/vmihailenco/msgpack/v5"
+	"io"
+	"math"
+	"strconv"
+)
+
+type Codec interface {
+	Extension() string
+	Encode(w io.Writer, data map[string]Value) error
+	Decode(r io.Reader) (map[string]Value, error)
+}
+
+var (
+	JSON        Codec = jsonCodec{indent: true}
+	CompactJSON Codec = jsonCodec{}
+	CBOR        Codec = cborCodec{}
+	MessagePack Codec = msgpackCodec{}
+)
+
+var codecs = []Codec{JSON, CBOR, MessagePack}
+
+type jsonCodec struct {
+	indent bool
+}
+
+func (c jsonCodec) Extension() string {
+	return "json"
+}
+
+func (c jsonCodec) Encode(w io.Writer, data map[string]Value) error {
+	enc := json.NewEncoder(w)
+
+	if c.indent {
+		enc.SetIndent("", "  ")
+	}
+
+	return enc.Encode(data)
+}
+
+func (c jsonCodec) Decode(r io.Reader) (map[string]Value, error) {
+	var d map[string]Value
+
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	err := dec.Decode(&d)
+	return d, err
+}
+
+type cborCodec struct{}
+
+func (c cborCodec) Extension() string {
+	return "cbor"
+}
+
+func (c cborCodec) Encode(w io.Writer, data map[string]Value) error {
+	return cbor.NewEncoder(w).Encode(data)
+}
+
+func (c cborCodec) Decode(r io.Reader) (map[string]Value, error) {
+	var d map[string]Value
+	err := cbor.NewDecoder(r).Decode(&d)
+	return d, err
+}
+
+type msgpackCodec struct{}
+
+func (c msgpackCodec) Extension() string {
+	return "msgpack"
+}
+
+func (c msgpackCodec) Encode(w io.Writer, data map[string]Value) error {
+	return msgpack.NewEncoder(w).Encode(data)
+}
+
+func (c msgpackCodec) Decode(r io.Reader) (map[string]Value, error) {
+	var d map[string]Value
+	err := msgpack.NewDecoder(r).Decode(&d)
+	return d, err
+}
+
+func asInt64(v any) (int64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		i, err := strconv.ParseInt(string(n), 10, 64)
+		return i, err == nil
+	case int64:
+		return n, true
+	case int8:
+		return int64(n), true
+	case int16:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case uint8:
+		return int64(n), true
+	case uint16:
+		return int64(n), true
+	case uint32:
+		return int64(n), true
+	case uint64:
+		return int64(n), n <= math.MaxInt64
+	}
+
+	return 0, false
+}
+
+func asUint64(v any) (uint64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		i, err := strconv.ParseUint(string(n), 10, 64)
+		return i, err == nil
+	case uint64:
+		return n, true
+	case uint8:
+		return uint64(n), true
+	case uint16:
+		return uint64(n), true
+	case uint32:
+		return uint64(n), true
+	case int64:
+		return uint64(n), n >= 0
+	case int8:
+		return uint64(n), n >= 0
+	case int16:
+		return uint64(n), n >= 0
+	case int32:
+		return uint64(n), n >= 0
+	}
+
+	return 0, false
+}
+
+func asFloat64(v any) (float64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		f, err := strconv.ParseFloat(string(n), 64)
+		return f, err == nil
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	}
+
+	if i, ok := asInt64(v); ok {
+		return float64(i), true
+	}
+
+	if u, ok := asUint64(v); ok {
+		return float64(u), true
+	}
+
+	return 0, false
+}
+
+func loadLoose(cache persistence.Section, k string, value any) {
+	switch n := value.(type) {
+	case json.Number:
+		if i, err := n.Int64(); err == nil {
+			cache.Set(k, i)
+		} else if u, err := strconv.ParseUint(string(n), 10, 64); err == nil {
+			cache.Set(k, u)
+		} else if f, err := n.Float64(); err == nil {
+			cache.Set(k, f)
+		}
+	case string, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		cache.Set(k, n)
+	}
+}
@@ -2,52 +2,108 @@ package file
 
 import (
 	"bytes"
-	"encoding/json"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"github.com/shimmeringbee/persistence"
 	"github.com/shimmeringbee/persistence/impl/memory"
+	"github.com/shimmeringbee/persistence/impl/readonly"
+	"io"
+	"io/fs"
+	"iter"
+	"maps"
 	"os"
-	"strconv"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
-func New(dir string) persistence.Section {
-	f := &file{m: &sync.RWMutex{}, cache: memory.New(), sections: make(map[string]*file)}
+func New(dir string, opts ...Option) persistence.Section {
+	o := defaultOptions()
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	st := &store{opts: o, lru: newLRU(o.maxLoadedSections)}
+
+	f := newFile(dir, st)
+	st.root = f
+
+	if !o.readOnly {
+		_ = o.fs.MkdirAll(f.dir, 0700)
+	}
+
+	if o.preload {
+		go f.preload()
+	}
+
+	if o.watchInterval > 0 {
+		go f.watchChanges(o.watchCtx, o.watchInterval)
+	}
+
+	if o.readOnly {
+		return readonly.New(f)
+	}
+
+	return f
+}
+
+type store struct {
+	opts *options
+	lru  *lru
+	root *file
+}
+
+func newFile(dir string, st *store) *file {
+	f := &file{m: &sync.RWMutex{}, sections: make(map[string]*file), store: st, watch: &watchState{m: &sync.Mutex{}}}
 
 	dirWithoutPathSep, _ := strings.CutSuffix(dir, string(os.PathSeparator))
 	f.dir = fmt.Sprintf("%s%c", dirWithoutPathSep, os.PathSeparator)
-	_ = os.MkdirAll(f.dir, 0700)
-
-	f.load()
 
 	return f
 }
 
 type file struct {
 	dir   string
-	cache persistence.Section
+	store *store
 
 	m        *sync.RWMutex
+	cache    persistence.Section
+	listed   bool
 	sections map[string]*file
 
-	dirtyTimer *time.Timer
+	modified     atomic.Bool
+	dirtyTimer   *time.Timer
+	sidecarsLost bool
+	unverified   bool
+
+	watch *watchState
 }
 
 var _ persistence.Section = (*file)(nil)
 var _ persistence.Syncer = (*file)(nil)
 
 func (f *file) Section(key ...string) persistence.Section {
+	if len(key) == 0 {
+		panic(fmt.Errorf("section: %w", persistence.ErrNoSectionKey))
+	}
+
 	f.m.Lock()
 	defer f.m.Unlock()
 
+	if err := f.listLocked(); err != nil {
+		panic(err)
+	}
+
 	s, ok := f.sections[key[0]]
 
 	if !ok {
-		dir := fmt.Sprintf("%s%s", f.dir, key[0])
-
-		s, _ = New(dir).(*file)
+		s = newFile(fmt.Sprintf("%s%s", f.dir, key[0]), f.store)
+		_ = f.store.opts.fs.MkdirAll(s.dir, 0700)
 		f.sections[key[0]] = s
 	}
 
@@ -58,7 +114,31 @@ func (f *file) Section(key ...string) persistence.Section {
 	}
 }
 
+func (f *file) SectionIfExists(key ...string) (persistence.Section, bool) {
+	if len(key) == 0 {
+		return nil, false
+	}
+
+	f.ensureListed()
+
+	f.m.RLock()
+	s, ok := f.sections[key[0]]
+	f.m.RUnlock()
+
+	if !ok {
+		return nil, false
+	}
+
+	if len(key) > 1 {
+		return s.SectionIfExists(key[1:]...)
+	} else {
+		return s, true
+	}
+}
+
 func (f *file) SectionKeys() []string {
+	f.ensureListed()
+
 	f.m.RLock()
 	defer f.m.RUnlock()
 
@@ -71,7 +151,44 @@ func (f *file) SectionKeys() []string {
 	return keys
 }
 
+func (f *file) AllSections(prefix ...string) iter.Seq2[string, persistence.Section] {
+	return func(yield func(string, persistence.Section) bool) {
+		keys := f.SectionKeys()
+		slices.Sort(keys)
+
+		for _, k := range keys {
+			if !hasPrefix(k, prefix) {
+				continue
+			}
+
+			f.m.RLock()
+			s, ok := f.sections[k]
+			f.m.RUnlock()
+
+			if ok && !yield(k, s) {
+				return
+			}
+		}
+	}
+}
+
+func hasPrefix(k string, prefix []string) bool {
+	if len(prefix) == 0 {
+		return true
+	}
+
+	for _, p := range prefix {
+		if strings.HasPrefix(k, p) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (f *file) SectionExists(key string) bool {
+	f.ensureListed()
+
 	f.m.RLock()
 	defer f.m.RUnlock()
 
@@ -84,6 +201,10 @@ func (f *file) SectionDelete(key string) bool {
 	f.m.Lock()
 	defer f.m.Unlock()
 
+	if err := f.listLocked(); err != nil {
+		panic(err)
+	}
+
 	if s, ok := f.sections[key]; ok {
 		for _, k := range s.SectionKeys() {
 			s.SectionDelete(k)
@@ -98,112 +219,441 @@ func (f *file) SectionDelete(key string) bool {
 }
 
 func (f *file) sectionDeleteSelf() {
-	_ = os.RemoveAll(f.dir)
+	f.stopDirtyTimer()
+	f.store.lru.remove(f)
+	_ = f.store.opts.fs.RemoveAll(f.dir)
+}
+
+var moveLock = &sync.Mutex{}
+
+func (f *file) SectionRename(key string, newKey string) bool {
+	return f.SectionMove(key, f, newKey)
+}
+
+func (f *file) SectionMove(key string, dstParent persistence.Section, dstKey string) bool {
+	dst, ok := dstParent.(*file)
+	if !ok || dst.store != f.store {
+		return persistence.MoveByCopy(f, key, dstParent, dstKey)
+	}
+
+	f.ensureListed()
+	dst.ensureListed()
+
+	moveLock.Lock()
+	defer moveLock.Unlock()
+
+	// Parent locks are always taken before their children, ordering by directory ensures the same here.
+	first, second := f, dst
+	if second.dir < first.dir {
+		first, second = second, first
+	}
+
+	first.m.Lock()
+	defer first.m.Unlock()
+
+	if second != first {
+		second.m.Lock()
+		defer second.m.Unlock()
+	}
+
+	s, found := f.sections[key]
+	if !found {
+		return false
+	}
+
+	if _, exists := dst.sections[dstKey]; exists {
+		return false
+	}
+
+	if strings.HasPrefix(dst.dir, s.dir) {
+		return false
+	}
+
+	newDir := fmt.Sprintf("%s%s%c", dst.dir, dstKey, os.PathSeparator)
+
+	s.lockTree()
+	defer s.unlockTree()
+
+	if err := f.store.opts.fs.Rename(strings.TrimSuffix(s.dir, string(os.PathSeparator)), strings.TrimSuffix(newDir, string(os.PathSeparator))); err != nil {
+		return false
+	}
+
+	s.relocate(newDir)
+
+	delete(f.sections, key)
+	dst.sections[dstKey] = s
+
+	return true
+}
+
+func (f *file) lockTree() {
+	f.m.Lock()
+
+	for _, s := range f.sections {
+		s.lockTree()
+	}
+}
+
+func (f *file) unlockTree() {
+	for _, s := range f.sections {
+		s.unlockTree()
+	}
+
+	f.m.Unlock()
+}
+
+func (f *file) relocate(dir string) {
+	f.dir = dir
+
+	for k, s := range f.sections {
+		s.relocate(fmt.Sprintf("%s%s%c", dir, k, os.PathSeparator))
+	}
+}
+
+func (f *file) data() persistence.Section {
+	for {
+		f.m.RLock()
+		c := f.cache
+		f.m.RUnlock()
+
+		if c != nil {
+			f.store.lru.touch(f)
+			return c
+		}
+
+		if err := f.loadData(); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func (f *file) write(fn func(persistence.Section)) {
+	for {
+		f.m.RLock()
+		if f.cache != nil {
+			break
+		}
+		f.m.RUnlock()
+
+		if err := f.loadData(); err != nil {
+			panic(err)
+		}
+	}
+
+	fn(f.cache)
+	f.modified.Store(true)
+	f.m.RUnlock()
+
+	f.store.lru.touch(f)
+	f.dirty()
+}
+
+func (f *file) evict() bool {
+	if !f.m.TryLock() {
+		return false
+	}
+	defer f.m.Unlock()
+
+	if f.dirtyTimer != nil || f.modified.Load() {
+		return false
+	}
+
+	f.cache = nil
+	return true
 }
 
 func (f *file) Keys() []string {
-	return f.cache.Keys()
+	return f.data().Keys()
+}
+
+func (f *file) All(prefix ...string) iter.Seq2[string, persistence.ValueType] {
+	return f.data().All(prefix...)
+}
+
+func (f *file) Walk(fn func(key string, vt persistence.ValueType, value any) bool, prefix ...string) {
+	f.data().Walk(fn, prefix...)
 }
 
 func (f *file) Exists(key string) bool {
-	return f.cache.Exists(key)
+	return f.data().Exists(key)
 }
 
 func (f *file) Type(key string) persistence.ValueType {
-	return f.cache.Type(key)
+	return f.data().Type(key)
+}
+
+func coerced[T any](f *file, fn func(persistence.Section, string, ...T) (T, bool, error), key string, defValue ...T) (T, bool) {
+	v, found, err := fn(f.data(), key, defValue...)
+
+	if errors.Is(err, persistence.ErrLossy) && f.store.opts.onLossy != nil {
+		f.store.opts.onLossy(err)
+	}
+
+	return v, found
 }
 
 func (f *file) Int(key string, defValue ...int64) (int64, bool) {
-	return f.cache.Int(key, defValue...)
+	if f.store.opts.coerce {
+		return coerced(f, persistence.CoerceInt, key, defValue...)
+	}
+
+	return f.data().Int(key, defValue...)
 }
 
 func (f *file) UInt(key string, defValue ...uint64) (uint64, bool) {
-	return f.cache.UInt(key, defValue...)
+	if f.store.opts.coerce {
+		return coerced(f, persistence.CoerceUInt, key, defValue...)
+	}
+
+	return f.data().UInt(key, defValue...)
 }
 
 func (f *file) String(key string, defValue ...string) (string, bool) {
-	return f.cache.String(key, defValue...)
+	return f.data().String(key, defValue...)
 }
 
 func (f *file) Bool(key string, defValue ...bool) (bool, bool) {
-	return f.cache.Bool(key, defValue...)
+	return f.data().Bool(key, defValue...)
 }
 
 func (f *file) Float(key string, defValue ...float64) (float64, bool) {
-	return f.cache.Float(key, defValue...)
+	if f.store.opts.coerce {
+		return coerced(f, persistence.CoerceFloat, key, defValue...)
+	}
+
+	return f.data().Float(key, defValue...)
 }
 
 func (f *file) Bytes(key string, defValue ...[]byte) ([]byte, bool) {
-	return f.cache.Bytes(key, defValue...)
+	return f.data().Bytes(key, defValue...)
 }
 
 func (f *file) Set(key string, value interface{}) {
-	f.cache.Set(key, value)
-	f.dirty()
+	f.write(func(c persistence.Section) {
+		c.Set(key, value)
+	})
 }
 
 func (f *file) Delete(key string) bool {
-	ok := f.cache.Delete(key)
-	f.dirty()
+	var ok bool
+
+	f.write(func(c persistence.Section) {
+		ok = c.Delete(key)
+	})
+
 	return ok
 }
 
-const dataFile = "data.json"
+const dataFilePrefix = "data."
+
+func (f *file) dataPath(c Codec) string {
+	return fmt.Sprintf("%s%s%s", f.dir, dataFilePrefix, c.Extension())
+}
+
+type loaded uint8
+
+const (
+	loadedCurrent loaded = iota
+	loadedPrevious
+	loadedUnverified
+)
+
+func (f *file) readData() (Codec, []byte, loaded, error) {
+	return readData(f.store.opts.fs.ReadFile, f.dataPath, f.store.opts)
+}
+
+func readData(read readFunc, dataPath func(Codec) string, opts *options) (Codec, []byte, loaded, error) {
+	for _, c := range append([]Codec{opts.codec}, codecs...) {
+		path := dataPath(c)
+
+		b, err := read(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, nil, loadedCurrent, err
+		}
+
+		err = verifyChecksum(read, path, b)
+		if err == nil {
+			return c, b, loadedCurrent, nil
+		} else if !errors.Is(err, ErrChecksumMismatch) {
+			return nil, nil, loadedCurrent, err
+		}
+
+		fn := opts.onChecksumMismatch
+		if fn != nil {
+			fn(err)
+		}
+
+		if opts.fallbackToPrevious {
+			if pb, perr := readVerified(read, path+previousSuffix); perr == nil {
+				return c, pb, loadedPrevious, nil
+			}
+		}
+
+		if fn == nil {
+			return nil, nil, loadedCurrent, err
+		}
+
+		return c, b, loadedUnverified, nil
+	}
+
+	if opts.fallbackToPrevious {
+		for _, c := range append([]Codec{opts.codec}, codecs...) {
+			if pb, err := readVerified(read, dataPath(c)+previousSuffix); err == nil {
+				return c, pb, loadedPrevious, nil
+			}
+		}
+	}
+
+	return nil, nil, loadedCurrent, fs.ErrNotExist
+}
 
 type Value struct {
-	Value any
-	Type  persistence.ValueType
+	Value    any
+	Type     persistence.ValueType
+	Encoding string `json:",omitempty" msgpack:",omitempty"`
 }
 
-func (f *file) load() {
-	var sections []string
-	dataPresent := false
+const (
+	encodingHex     = ""
+	encodingBase64  = "base64"
+	encodingSidecar = "sidecar"
+)
 
-	entries, err := os.ReadDir(f.dir)
-	if err != nil {
+func (f *file) ensureListed() {
+	f.m.RLock()
+	listed := f.listed
+	f.m.RUnlock()
+
+	if listed {
+		return
+	}
+
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	if err := f.listLocked(); err != nil {
 		panic(err)
 	}
+}
+
+func (f *file) listLocked() error {
+	if f.listed {
+		return nil
+	}
+
+	entries, err := f.store.opts.fs.ReadDir(f.dir)
+	if err != nil {
+		if f.store.opts.readOnly && errors.Is(err, fs.ErrNotExist) {
+			f.listed = true
+			return nil
+		}
+
+		return err
+	}
 
 	for _, ent := range entries {
 		if ent.IsDir() {
-			sections = append(sections, ent.Name())
-		} else if ent.Name() == dataFile {
-			dataPresent = true
+			f.sections[ent.Name()] = newFile(fmt.Sprintf("%s%s", f.dir, ent.Name()), f.store)
 		}
 	}
 
-	if dataPresent {
-		var d map[string]Value
+	f.listed = true
+	return nil
+}
+
+func (f *file) loadData() error {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	if f.cache != nil {
+		return nil
+	}
+
+	cache := memory.New()
+	lost := false
+
+	codec, b, gen, err := f.readData()
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
 
-		b, err := os.ReadFile(fmt.Sprintf("%s%s", f.dir, dataFile))
+		codec = f.store.opts.codec
+	} else {
+		d, err := codec.Decode(bytes.NewReader(b))
 		if err != nil {
-			panic(err)
+			return err
 		}
 
-		dec := json.NewDecoder(bytes.NewReader(b))
-		dec.UseNumber()
+		for k, v := range d {
+			if err := loadValue(cache, k, v, f.readSidecar, f.store.opts.coerce); err != nil {
+				if !sidecarLost(err) {
+					return err
+				}
 
-		if err := dec.Decode(&d); err != nil {
-			panic(err)
+				lost = true
+			}
 		}
+	}
 
-		for k, v := range d {
-			f.loadValue(k, v)
+	if f.watching() {
+		base := memory.New()
+		persistence.Copy(base, cache)
+
+		if err := f.recordOnDisk(codec, base); err != nil {
+			return err
 		}
 	}
 
-	for _, subsection := range sections {
-		s := New(fmt.Sprintf("%s%s", f.dir, subsection)).(*file)
-		f.sections[subsection] = s
+	f.cache = cache
+	f.sidecarsLost = lost
+	f.unverified = gen == loadedUnverified
+
+	if gen == loadedPrevious && !f.store.opts.readOnly {
+		f.modified.Store(true)
+		f.dirtyLocked()
+	}
+
+	return nil
+}
+
+func (f *file) preload() {
+	if err := f.loadData(); err != nil {
+		return
+	}
+
+	f.store.lru.touch(f)
+
+	f.m.Lock()
+	err := f.listLocked()
+	sections := slices.Collect(maps.Values(f.sections))
+	f.m.Unlock()
+
+	if err != nil {
+		return
+	}
+
+	for _, s := range sections {
+		s.preload()
 	}
 }
 
 const dirtyDelay = 500 * time.Millisecond
 
 func (f *file) dirty() {
+	if f.store.opts.readOnly {
+		return
+	}
+
 	f.m.Lock()
 	defer f.m.Unlock()
 
+	f.dirtyLocked()
+}
+
+func (f *file) dirtyLocked() {
 	if f.dirtyTimer != nil {
 		f.dirtyTimer.Stop()
 	}
@@ -212,122 +662,264 @@ func (f *file) dirty() {
 }
 
 func (f *file) dirtySync() {
+	f.sync(false)
+}
+
+func (f *file) stopDirtyTimer() {
 	f.m.Lock()
+	defer f.m.Unlock()
 
 	if f.dirtyTimer != nil {
 		f.dirtyTimer.Stop()
 		f.dirtyTimer = nil
 	}
+}
 
-	f.m.Unlock()
+func loadValue(cache persistence.Section, k string, v Value, readSidecar func(string) ([]byte, error), coerce bool) error {
+	loaded, err := loadTyped(cache, k, v, readSidecar)
+	if err != nil {
+		return err
+	}
 
-	f.sync(false)
+	if !loaded && coerce {
+		loadLoose(cache, k, v.Value)
+	}
+
+	return nil
 }
 
-func (f *file) loadValue(k string, v Value) {
+func loadTyped(cache persistence.Section, k string, v Value, readSidecar func(string) ([]byte, error)) (bool, error) {
 	switch v.Type {
 	case persistence.Int:
-		if jn, ok := v.Value.(json.Number); ok {
-			if n, err := strconv.Atoi(string(jn)); err == nil {
-				f.cache.Set(k, int64(n))
-			}
+		if n, ok := asInt64(v.Value); ok {
+			cache.Set(k, n)
+			return true, nil
 		}
 	case persistence.UnsignedInt:
-		if jn, ok := v.Value.(json.Number); ok {
-			if n, err := strconv.Atoi(string(jn)); err == nil {
-				f.cache.Set(k, uint64(n))
-			}
+		if n, ok := asUint64(v.Value); ok {
+			cache.Set(k, n)
+			return true, nil
 		}
 	case persistence.String:
 		if s, ok := v.Value.(string); ok {
-			f.cache.Set(k, s)
+			cache.Set(k, s)
+			return true, nil
 		}
 	case persistence.Bool:
 		if b, ok := v.Value.(bool); ok {
-			f.cache.Set(k, b)
+			cache.Set(k, b)
+			return true, nil
 		}
 	case persistence.Float:
-		if jn, ok := v.Value.(json.Number); ok {
-			if n, err := strconv.ParseFloat(string(jn), 64); err == nil {
-				f.cache.Set(k, n)
-			}
+		if n, ok := asFloat64(v.Value); ok {
+			cache.Set(k, n)
+			return true, nil
 		}
 	case persistence.Bytes:
 		if ba, ok := v.Value.(string); ok {
 			var data []byte
-
-			for ; len(ba) > 0; ba = ba[2:] {
-				if b, err := strconv.ParseInt(ba[:2], 16, 8); err == nil {
-					data = append(data, byte(b))
-				} else {
-					return
+			var err error
+
+			switch v.Encoding {
+			case encodingHex:
+				data, err = hex.DecodeString(ba)
+			case encodingBase64:
+				data, err = base64.StdEncoding.DecodeString(ba)
+			case encodingSidecar:
+				if data, err = readSidecar(ba); err != nil {
+					return false, err
 				}
+			default:
+				return false, nil
 			}
 
-			f.cache.Set(k, data)
+			if err == nil {
+				cache.Set(k, data)
+				return true, nil
+			}
 		}
 	}
+
+	return false, nil
 }
 
 func (f *file) Sync() {
 	f.sync(true)
+
+	if f == f.store.root && f.store.opts.backupGenerations > 0 && !f.store.opts.readOnly {
+		if err := f.snapshot(); err != nil {
+			panic(err)
+		}
+	}
 }
 
 func (f *file) sync(recursive bool) {
+	if f.store.opts.readOnly {
+		return
+	}
+
+	f.stopDirtyTimer()
+
+	if f.watching() {
+		if change := f.reconcile(true); change != nil {
+			f.notify(*change)
+		}
+	}
+
 	f.m.RLock()
 	defer f.m.RUnlock()
 
+	if f.cache != nil {
+		f.modified.Store(false)
+
+		if err := f.writeData(); err != nil {
+			panic(err)
+		}
+	}
+
+	if recursive {
+		for _, v := range f.sections {
+			v.sync(recursive)
+		}
+	}
+}
+
+func (f *file) writeData() error {
 	data := make(map[string]Value)
+	sidecars := make(map[string]struct{})
+
+	src := f.cache
+
+	if f.watching() {
+		src = memory.New()
+		persistence.Copy(src, f.cache)
+	}
 
-	for _, k := range f.cache.Keys() {
+	for _, k := range src.Keys() {
+		var encoding string
 		var v any
-		t := f.cache.Type(k)
+		t := src.Type(k)
 
 		switch t {
 		case persistence.Int:
-			v, _ = f.cache.Int(k)
+			v, _ = src.Int(k)
 		case persistence.UnsignedInt:
-			v, _ = f.cache.UInt(k)
+			v, _ = src.UInt(k)
 		case persistence.String:
-			v, _ = f.cache.String(k)
+			v, _ = src.String(k)
 		case persistence.Bool:
-			v, _ = f.cache.Bool(k)
+			v, _ = src.Bool(k)
 		case persistence.Float:
-			v, _ = f.cache.Float(k)
+			v, _ = src.Float(k)
 		case persistence.Bytes:
-			bs, _ := f.cache.Bytes(k)
+			bs, _ := src.Bytes(k)
 
-			var bytesOut []string
+			if threshold := f.store.opts.sidecarThreshold; threshold > 0 && len(bs) > threshold {
+				name, err := f.writeSidecar(bs)
+				if err != nil {
+					return err
+				}
 
-			for _, b := range bs {
-				bytesOut = append(bytesOut, fmt.Sprintf("%02x", b))
+				sidecars[name] = struct{}{}
+				v, encoding = name, encodingSidecar
+			} else {
+				v, encoding = base64.StdEncoding.EncodeToString(bs), encodingBase64
 			}
-
-			v = strings.Join(bytesOut, "")
 		}
 
 		data[k] = Value{
-			Value: v,
-			Type:  t,
+			Value:    v,
+			Type:     t,
+			Encoding: encoding,
 		}
 	}
 
-	r, err := os.Create(fmt.Sprintf("%s%s", f.dir, dataFile))
-	if err != nil {
-		panic(err)
+	codec := f.store.opts.codec
+	path := f.dataPath(codec)
+
+	buf := &bytes.Buffer{}
+
+	if err := codec.Encode(buf, data); err != nil {
+		return err
 	}
-	defer r.Close()
 
-	enc := json.NewEncoder(r)
-	enc.SetIndent("", "  ")
+	if f.store.opts.fallbackToPrevious {
+		if err := rotatePrevious(f.store.opts.fs, path); err != nil {
+			return err
+		}
 
-	if err := enc.Encode(data); err != nil {
-		panic(err)
+		if err := referencedSidecars(f.store.opts.fs.ReadFile, codec, path+previousSuffix, sidecars); err != nil {
+			return err
+		}
 	}
 
-	if recursive {
-		for _, v := range f.sections {
-			v.sync(recursive)
+	if err := writeFileAtomic(f.store.opts.fs, path, buf.Bytes()); err != nil {
+		return err
+	}
+
+	if !f.unverified {
+		if err := writeChecksum(f.store.opts.fs, path, f.store.opts.checksum, buf.Bytes()); err != nil {
+			return err
 		}
 	}
+
+	for _, c := range codecs {
+		if c.Extension() != codec.Extension() {
+			if err := removeWithGenerations(f.store.opts.fs, f.dataPath(c)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !f.sidecarsLost {
+		if err := f.removeUnusedSidecars(sidecars); err != nil {
+			return err
+		}
+	}
+
+	if f.watching() {
+		return f.recordOnDisk(codec, src)
+	}
+
+	return nil
+}
+
+func writeFileAtomic(fsys FS, path string, data []byte) error {
+	tmp := path + ".tmp"
+
+	w, err := fsys.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	if err := writeAndSync(w, data); err != nil {
+		_ = w.Close()
+		_ = fsys.Remove(tmp)
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		_ = fsys.Remove(tmp)
+		return err
+	}
+
+	if err := fsys.Rename(tmp, path); err != nil {
+		_ = fsys.Remove(tmp)
+		return err
+	}
+
+	return nil
+}
+
+func writeAndSync(w File, data []byte) error {
+	n, err := w.Write(data)
+	if err != nil {
+		return err
+	}
+
+	if n < len(data) {
+		return io.ErrShortWrite
+	}
+
+	return w.Sync()
 }
@@ -1,16 +1,22 @@
 package file
 
 import (
+	"bytes"
 	"github.com/shimmeringbee/persistence"
 	"github.com/shimmeringbee/persistence/impl/test"
+	"github.com/stretchr/testify/assert"
+	"math"
 	"os"
+	"path/filepath"
 	"sync"
 	"testing"
+	"time"
 )
 
 type tracker struct {
-	m  *sync.Mutex
-	db map[persistence.Section]string
+	m    *sync.Mutex
+	db   map[persistence.Section]string
+	opts []Option
 }
 
 func (t *tracker) New() persistence.Section {
@@ -26,7 +32,7 @@ func (t *tracker) New() persistence.Section {
 }
 
 func (t *tracker) new(dir string) persistence.Section {
-	p := New(dir)
+	p := New(dir, t.opts...)
 	t.db[p] = dir
 
 	return p
@@ -59,12 +65,612 @@ func (t *tracker) Done(p persistence.Section) {
 
 	delete(t.db, p)
 
+	if f, ok := p.(*file); ok {
+		stopDirtyTimers(f)
+	}
+
 	if err := os.RemoveAll(dir); err != nil {
 		panic(err)
 	}
 }
 
+func stopDirtyTimers(f *file) {
+	f.stopDirtyTimer()
+
+	f.m.RLock()
+	defer f.m.RUnlock()
+
+	for _, s := range f.sections {
+		stopDirtyTimers(s)
+	}
+}
+
 func TestFile(t *testing.T) {
 	tr := tracker{m: &sync.Mutex{}, db: make(map[persistence.Section]string)}
 	test.Impl{New: tr.New, Switch: tr.Switch, Done: tr.Done}.Test(t)
 }
+
+func TestFile_Codecs(t *testing.T) {
+	for name, codec := range map[string]Codec{"CompactJSON": CompactJSON, "CBOR": CBOR, "MessagePack": MessagePack} {
+		t.Run(name, func(t *testing.T) {
+			tr := tracker{m: &sync.Mutex{}, db: make(map[persistence.Section]string), opts: []Option{WithCodec(codec)}}
+			test.Impl{New: tr.New, Switch: tr.Switch, Done: tr.Done}.Test(t)
+		})
+	}
+}
+
+func TestFile_Evicting(t *testing.T) {
+	tr := tracker{m: &sync.Mutex{}, db: make(map[persistence.Section]string), opts: []Option{MaxLoadedSections(1)}}
+	test.Impl{New: tr.New, Switch: tr.Switch, Done: tr.Done}.Test(t)
+}
+
+func TestFile_Coercing(t *testing.T) {
+	tr := tracker{m: &sync.Mutex{}, db: make(map[persistence.Section]string), opts: []Option{Coerce(nil)}}
+	test.Impl{New: tr.New, Switch: tr.Switch, Done: tr.Done}.TestCoercing(t)
+}
+
+func TestFile_Coerce(t *testing.T) {
+	edited := `{
+		"quoted": {"Value": "42", "Type": 0},
+		"fraction": {"Value": 2.5, "Type": 0},
+		"negative": {"Value": -1, "Type": 1},
+		"float": {"Value": 3, "Type": 4}
+	}`
+
+	t.Run("hand edited values with mismatched types are coerced", func(t *testing.T) {
+		dir := t.TempDir()
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "data.json"), []byte(edited), 0600))
+
+		var reported []error
+
+		s := New(dir, Coerce(func(err error) {
+			reported = append(reported, err)
+		})).(*file)
+		defer stopDirtyTimers(s)
+
+		i, found := s.Int("quoted")
+		assert.True(t, found)
+		assert.Equal(t, int64(42), i)
+
+		i, found = s.Int("negative")
+		assert.True(t, found)
+		assert.Equal(t, int64(-1), i)
+
+		f, found := s.Float("float")
+		assert.True(t, found)
+		assert.Equal(t, 3.0, f)
+
+		_, found = s.Int("fraction")
+		assert.False(t, found)
+
+		assert.Len(t, reported, 1)
+		assert.ErrorIs(t, reported[0], persistence.ErrLossy)
+	})
+
+	t.Run("hand edited values with mismatched types are dropped without coercion", func(t *testing.T) {
+		dir := t.TempDir()
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "data.json"), []byte(edited), 0600))
+
+		s := New(dir).(*file)
+		defer stopDirtyTimers(s)
+
+		assert.False(t, s.Exists("quoted"))
+		assert.False(t, s.Exists("fraction"))
+	})
+}
+
+func TestFile_SectionRename(t *testing.T) {
+	t.Run("renaming a section renames its directory", func(t *testing.T) {
+		dir, err := os.MkdirTemp("", "*")
+		assert.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		s := New(dir)
+		s.Section("old", "child")
+
+		assert.True(t, s.SectionRename("old", "new"))
+
+		assert.NoDirExists(t, filepath.Join(dir, "old"))
+		assert.DirExists(t, filepath.Join(dir, "new", "child"))
+	})
+}
+
+func TestFile_SectionMove(t *testing.T) {
+	t.Run("moving a section between stores copies it", func(t *testing.T) {
+		srcDir := t.TempDir()
+		dstDir := t.TempDir()
+
+		src := New(srcDir).(*file)
+		defer stopDirtyTimers(src)
+
+		dst := New(dstDir, WithFS(NewMemFS())).(*file)
+		defer stopDirtyTimers(dst)
+
+		src.Section("old").Set("key", "value")
+		src.Section("old", "child").Set("nested", int64(1))
+		src.Sync()
+
+		assert.True(t, src.SectionMove("old", dst, "new"))
+
+		assert.False(t, src.SectionExists("old"))
+		assert.NoDirExists(t, filepath.Join(srcDir, "old"))
+
+		v, _ := dst.Section("new").String("key")
+		assert.Equal(t, "value", v)
+
+		n, _ := dst.Section("new", "child").Int("nested")
+		assert.Equal(t, int64(1), n)
+
+		dst.Sync()
+
+		_, err := dst.store.opts.fs.Stat(filepath.Join(dstDir, "new", "child", "data.json"))
+		assert.NoError(t, err)
+		assert.NoDirExists(t, filepath.Join(dstDir, "new"))
+	})
+}
+
+func TestFile_ReadOnly(t *testing.T) {
+	t.Run("existing data can be read but not modified", func(t *testing.T) {
+		dir, err := os.MkdirTemp("", "*")
+		assert.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		w := New(dir)
+		w.Set("key", "value")
+		w.Section("a").Set("key", 1)
+		w.(persistence.Syncer).Sync()
+		stopDirtyTimers(w.(*file))
+
+		ro := New(dir, ReadOnly())
+
+		v, _ := ro.String("key")
+		assert.Equal(t, "value", v)
+
+		i, _ := ro.Section("a").Int("key")
+		assert.Equal(t, int64(1), i)
+
+		assert.Panics(t, func() { ro.Set("key", "other") })
+		assert.Panics(t, func() { ro.SectionDelete("a") })
+	})
+
+	t.Run("nothing is written to disk", func(t *testing.T) {
+		dir, err := os.MkdirTemp("", "*")
+		assert.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		missing := filepath.Join(dir, "missing")
+
+		ro := New(missing, ReadOnly())
+		ro.Section("a", "b")
+
+		assert.False(t, ro.SectionExists("a"))
+		assert.NoDirExists(t, missing)
+	})
+}
+
+func TestFile_Lazy(t *testing.T) {
+	populate := func(t *testing.T) string {
+		dir, err := os.MkdirTemp("", "*")
+		assert.NoError(t, err)
+
+		w := New(dir)
+		w.Section("a").Set("key", "a")
+		w.Section("b").Set("key", "b")
+		w.Section("a", "c").Set("key", "c")
+		w.(persistence.Syncer).Sync()
+		stopDirtyTimers(w.(*file))
+
+		return dir
+	}
+
+	t.Run("subsections are not loaded until accessed", func(t *testing.T) {
+		dir := populate(t)
+		defer os.RemoveAll(dir)
+
+		f := New(dir).(*file)
+		assert.False(t, f.listed)
+
+		a := f.Section("a").(*file)
+		assert.True(t, f.listed)
+		assert.Nil(t, a.cache)
+		assert.False(t, a.listed)
+
+		v, _ := a.String("key")
+		assert.Equal(t, "a", v)
+		assert.NotNil(t, a.cache)
+		assert.False(t, a.listed)
+	})
+
+	t.Run("least recently used unmodified sections are evicted", func(t *testing.T) {
+		dir := populate(t)
+		defer os.RemoveAll(dir)
+
+		f := New(dir, MaxLoadedSections(1)).(*file)
+
+		a := f.Section("a").(*file)
+		b := f.Section("b").(*file)
+
+		a.Keys()
+		assert.NotNil(t, a.cache)
+
+		b.Keys()
+		assert.NotNil(t, b.cache)
+		assert.Nil(t, a.cache)
+
+		v, _ := a.String("key")
+		assert.Equal(t, "a", v)
+		assert.Nil(t, b.cache)
+	})
+
+	t.Run("modified sections are not evicted until synced", func(t *testing.T) {
+		dir := populate(t)
+		defer os.RemoveAll(dir)
+
+		f := New(dir, MaxLoadedSections(1)).(*file)
+		defer stopDirtyTimers(f)
+
+		a := f.Section("a").(*file)
+		b := f.Section("b").(*file)
+
+		a.Set("key", "modified")
+		b.Keys()
+		assert.NotNil(t, a.cache)
+
+		a.Sync()
+		b.Keys()
+		assert.Nil(t, a.cache)
+
+		v, _ := a.String("key")
+		assert.Equal(t, "modified", v)
+	})
+
+	t.Run("preload loads all sections in the background", func(t *testing.T) {
+		dir := populate(t)
+		defer os.RemoveAll(dir)
+
+		f := New(dir, Preload()).(*file)
+
+		assert.Eventually(t, func() bool {
+			c, ok := f.SectionIfExists("a", "c")
+			if !ok {
+				return false
+			}
+
+			c.(*file).m.RLock()
+			defer c.(*file).m.RUnlock()
+
+			return c.(*file).cache != nil
+		}, time.Second, 10*time.Millisecond)
+	})
+}
+
+func TestFile_Bytes(t *testing.T) {
+	t.Run("bytes are written as base64", func(t *testing.T) {
+		dir, err := os.MkdirTemp("", "*")
+		assert.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		f := New(dir).(*file)
+		f.Set("key", []byte{0x01, 0xff})
+		f.Sync()
+
+		data, err := os.ReadFile(filepath.Join(dir, "data.json"))
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), `"Value": "Af8="`)
+		assert.Contains(t, string(data), `"Encoding": "base64"`)
+	})
+
+	t.Run("legacy hex encoded bytes are read", func(t *testing.T) {
+		dir, err := os.MkdirTemp("", "*")
+		assert.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		err = os.WriteFile(filepath.Join(dir, "data.json"), []byte(`{"key": {"Value": "01ff", "Type": 5}}`), 0600)
+		assert.NoError(t, err)
+
+		v, found := New(dir).Bytes("key")
+		assert.True(t, found)
+		assert.Equal(t, []byte{0x01, 0xff}, v)
+	})
+
+	t.Run("bytes over the threshold are stored in sidecar files", func(t *testing.T) {
+		dir, err := os.MkdirTemp("", "*")
+		assert.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		large := bytes.Repeat([]byte{0xaa}, 16)
+
+		f := New(dir, SidecarThreshold(8)).(*file)
+		f.Set("large", large)
+		f.Set("small", []byte{0x01})
+		f.Sync()
+
+		sidecar := filepath.Join(dir, sidecarName(large)+sidecarSuffix)
+		assert.FileExists(t, sidecar)
+
+		r := New(dir, SidecarThreshold(8))
+
+		v, found := r.Bytes("large")
+		assert.True(t, found)
+		assert.Equal(t, large, v)
+
+		v, found = r.Bytes("small")
+		assert.True(t, found)
+		assert.Equal(t, []byte{0x01}, v)
+
+		f.Delete("large")
+		f.Sync()
+
+		assert.NoFileExists(t, sidecar)
+	})
+
+	t.Run("sidecar files which do not match their hash are ignored", func(t *testing.T) {
+		dir, err := os.MkdirTemp("", "*")
+		assert.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		large := bytes.Repeat([]byte{0xaa}, 16)
+
+		f := New(dir, SidecarThreshold(8)).(*file)
+		f.Set("large", large)
+		f.Sync()
+
+		err = os.WriteFile(filepath.Join(dir, sidecarName(large)+sidecarSuffix), []byte{0x00}, 0600)
+		assert.NoError(t, err)
+
+		r := New(dir, SidecarThreshold(8)).(*file)
+
+		_, found := r.Bytes("large")
+		assert.False(t, found)
+
+		r.Set("other", "value")
+		r.Sync()
+
+		assert.FileExists(t, filepath.Join(dir, sidecarName(large)+sidecarSuffix))
+	})
+}
+
+func TestFile_Codec(t *testing.T) {
+	t.Run("existing data in another format is detected and migrated on sync", func(t *testing.T) {
+		dir, err := os.MkdirTemp("", "*")
+		assert.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		w := New(dir).(*file)
+		w.Set("key", "value")
+		w.Sync()
+
+		assert.FileExists(t, filepath.Join(dir, "data.json"))
+
+		r := New(dir, WithCodec(CBOR)).(*file)
+
+		v, found := r.String("key")
+		assert.True(t, found)
+		assert.Equal(t, "value", v)
+
+		r.Set("other", 1)
+		r.Sync()
+
+		assert.NoFileExists(t, filepath.Join(dir, "data.json"))
+		assert.FileExists(t, filepath.Join(dir, "data.cbor"))
+
+		v, found = New(dir, WithCodec(MessagePack)).String("key")
+		assert.True(t, found)
+		assert.Equal(t, "value", v)
+	})
+
+	t.Run("numeric extremes survive every codec", func(t *testing.T) {
+		for _, codec := range []Codec{JSON, CompactJSON, CBOR, MessagePack} {
+			dir, err := os.MkdirTemp("", "*")
+			assert.NoError(t, err)
+
+			w := New(dir, WithCodec(codec)).(*file)
+			w.Set("uint", uint64(math.MaxUint64))
+			w.Set("int", int64(math.MinInt64))
+			w.Set("float", 1.0)
+			w.Sync()
+
+			r := New(dir, WithCodec(codec))
+
+			u, _ := r.UInt("uint")
+			assert.Equal(t, uint64(math.MaxUint64), u, codec.Extension())
+
+			i, _ := r.Int("int")
+			assert.Equal(t, int64(math.MinInt64), i, codec.Extension())
+
+			f, found := r.Float("float")
+			assert.True(t, found, codec.Extension())
+			assert.Equal(t, 1.0, f, codec.Extension())
+
+			_ = os.RemoveAll(dir)
+		}
+	})
+}
+
+func TestFile_Checksum(t *testing.T) {
+	write := func(t *testing.T, dir string, value string, opts ...Option) {
+		w := New(dir, opts...).(*file)
+		w.Set("key", value)
+		w.Sync()
+	}
+
+	corrupt := func(t *testing.T, dir string) {
+		path := filepath.Join(dir, "data.json")
+
+		b, err := os.ReadFile(path)
+		assert.NoError(t, err)
+
+		b = bytes.Replace(b, []byte("value"), []byte("VALUE"), 1)
+		assert.NoError(t, os.WriteFile(path, b, 0600))
+	}
+
+	t.Run("checksum is written alongside data", func(t *testing.T) {
+		dir, err := os.MkdirTemp("", "*")
+		assert.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		write(t, dir, "value", Checksum(SHA256))
+
+		sum, err := os.ReadFile(filepath.Join(dir, "data.json.sum"))
+		assert.NoError(t, err)
+		assert.Regexp(t, "^sha256:[0-9a-f]{64}\n$", string(sum))
+	})
+
+	t.Run("mismatched checksum is reported and the data is still loaded", func(t *testing.T) {
+		dir, err := os.MkdirTemp("", "*")
+		assert.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		write(t, dir, "value")
+		corrupt(t, dir)
+
+		var reported error
+
+		r := New(dir, OnChecksumMismatch(func(err error) {
+			reported = err
+		})).(*file)
+		defer stopDirtyTimers(r)
+
+		v, found := r.String("key")
+		assert.True(t, found)
+		assert.Equal(t, "VALUE", v)
+		assert.ErrorIs(t, reported, ErrChecksumMismatch)
+
+		r.Set("other", "value")
+		r.Sync()
+
+		err = recovered(func() { New(dir).String("key") })
+		assert.ErrorIs(t, err, ErrChecksumMismatch)
+	})
+
+	t.Run("mismatched checksum refuses to load without a handler", func(t *testing.T) {
+		dir := t.TempDir()
+
+		write(t, dir, "value")
+		corrupt(t, dir)
+
+		err := recovered(func() { New(dir).String("key") })
+		assert.ErrorIs(t, err, ErrChecksumMismatch)
+	})
+
+	t.Run("falls back to the previous good generation", func(t *testing.T) {
+		dir, err := os.MkdirTemp("", "*")
+		assert.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		write(t, dir, "first", FallbackToPrevious())
+		write(t, dir, "value", FallbackToPrevious())
+		corrupt(t, dir)
+
+		reported := false
+
+		r := New(dir, FallbackToPrevious(), OnChecksumMismatch(func(err error) {
+			reported = true
+		})).(*file)
+		defer stopDirtyTimers(r)
+
+		v, found := r.String("key")
+		assert.True(t, found)
+		assert.Equal(t, "first", v)
+		assert.True(t, reported)
+
+		r.Sync()
+
+		v, _ = New(dir).String("key")
+		assert.Equal(t, "first", v)
+	})
+
+	t.Run("falling back in a read only store leaves the section clean", func(t *testing.T) {
+		dir := t.TempDir()
+
+		write(t, dir, "first", FallbackToPrevious())
+		write(t, dir, "value", FallbackToPrevious())
+		corrupt(t, dir)
+
+		o := defaultOptions()
+		ReadOnly()(o)
+		FallbackToPrevious()(o)
+
+		st := &store{opts: o, lru: newLRU(o.maxLoadedSections)}
+		r := newFile(dir, st)
+		st.root = r
+		defer stopDirtyTimers(r)
+
+		v, _ := r.String("key")
+		assert.Equal(t, "first", v)
+
+		assert.False(t, r.modified.Load())
+		assert.Nil(t, r.dirtyTimer)
+
+		b, err := os.ReadFile(filepath.Join(dir, "data.json"))
+		assert.NoError(t, err)
+		assert.Contains(t, string(b), "VALUE")
+	})
+
+	t.Run("falls back to the previous generation when the data file is missing", func(t *testing.T) {
+		dir := t.TempDir()
+
+		write(t, dir, "first", FallbackToPrevious())
+		write(t, dir, "value", FallbackToPrevious())
+
+		assert.FileExists(t, filepath.Join(dir, "data.json.prev"))
+		assert.NoError(t, os.Remove(filepath.Join(dir, "data.json")))
+
+		r := New(dir, FallbackToPrevious()).(*file)
+		defer stopDirtyTimers(r)
+
+		v, found := r.String("key")
+		assert.True(t, found)
+		assert.Equal(t, "first", v)
+	})
+
+	t.Run("falls back to a previous generation with sidecar values", func(t *testing.T) {
+		dir := t.TempDir()
+		opts := []Option{FallbackToPrevious(), SidecarThreshold(8)}
+
+		first := bytes.Repeat([]byte{0x01}, 16)
+		second := bytes.Repeat([]byte{0x02}, 16)
+
+		w := New(dir, opts...).(*file)
+		defer stopDirtyTimers(w)
+
+		w.Set("key", "value")
+		w.Set("large", first)
+		w.Sync()
+
+		w.Set("large", second)
+		w.Sync()
+
+		assert.FileExists(t, filepath.Join(dir, sidecarName(first)+sidecarSuffix))
+
+		corrupt(t, dir)
+
+		r := New(dir, opts...).(*file)
+		defer stopDirtyTimers(r)
+
+		v, found := r.Bytes("large")
+		assert.True(t, found)
+		assert.Equal(t, first, v)
+
+		r.Sync()
+
+		v, found = New(dir, opts...).Bytes("large")
+		assert.True(t, found)
+		assert.Equal(t, first, v)
+	})
+
+	t.Run("data without a checksum is accepted", func(t *testing.T) {
+		dir, err := os.MkdirTemp("", "*")
+		assert.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		write(t, dir, "value", Checksum(NoChecksum))
+		assert.NoFileExists(t, filepath.Join(dir, "data.json.sum"))
+
+		corrupt(t, dir)
+
+		v, found := New(dir).String("key")
+		assert.True(t, found)
+		assert.Equal(t, "VALUE", v)
+	})
+}
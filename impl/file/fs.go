@@ -0,0 +1,94 @@
+package file
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+type FS interface {
+	ReadDir(name string) ([]fs.DirEntry, error)
+	ReadFile(name string) ([]byte, error)
+	Stat(name string) (fs.FileInfo, error)
+	OpenFile(name string, flag int, perm fs.FileMode) (File, error)
+	MkdirAll(name string, perm fs.FileMode) error
+	Rename(oldpath string, newpath string) error
+	Remove(name string) error
+	RemoveAll(name string) error
+	Link(oldname string, newname string) error
+}
+
+type File interface {
+	io.Writer
+	Sync() error
+	Close() error
+}
+
+var OS FS = osFS{}
+
+type osFS struct{}
+
+func (osFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return os.ReadDir(name)
+}
+
+func (osFS) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+func (osFS) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
+
+func (osFS) OpenFile(name string, flag int, perm fs.FileMode) (File, error) {
+	return os.OpenFile(name, flag, perm)
+}
+
+func (osFS) MkdirAll(name string, perm fs.FileMode) error {
+	return os.MkdirAll(name, perm)
+}
+
+func (osFS) Rename(oldpath string, newpath string) error {
+	return os.Rename(oldpath, newpath)
+}
+
+func (osFS) Remove(name string) error {
+	return os.Remove(name)
+}
+
+func (osFS) RemoveAll(name string) error {
+	return os.RemoveAll(name)
+}
+
+func (osFS) Link(oldname string, newname string) error {
+	return os.Link(oldname, newname)
+}
+
+func walkDir(fsys FS, dir string, fn func(p string, d fs.DirEntry) error) error {
+	entries, err := fsys.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+
+	for _, ent := range entries {
+		p := filepath.Join(dir, ent.Name())
+
+		if err := fn(p, ent); err != nil {
+			return err
+		}
+
+		if ent.IsDir() {
+			if err := walkDir(fsys, p, fn); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
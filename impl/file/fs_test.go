@@ -0,0 +1,377 @@
+package file
+
+import (
+	"errors"
+	"github.com/shimmeringbee/persistence"
+	"github.com/shimmeringbee/persistence/impl/test"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+)
+
+type fault struct {
+	op      string
+	suffix  string
+	err     error
+	partial int
+}
+
+type faultFS struct {
+	FS
+	m      *sync.Mutex
+	faults []fault
+}
+
+func newFaultFS(fsys FS) *faultFS {
+	return &faultFS{FS: fsys, m: &sync.Mutex{}}
+}
+
+func (f *faultFS) inject(ft fault) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	f.faults = append(f.faults, ft)
+}
+
+func (f *faultFS) clear() {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	f.faults = nil
+}
+
+func (f *faultFS) match(op string, name string) (fault, bool) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	for _, ft := range f.faults {
+		if ft.op == op && strings.HasSuffix(name, ft.suffix) {
+			return ft, true
+		}
+	}
+
+	return fault{}, false
+}
+
+func (f *faultFS) ReadFile(name string) ([]byte, error) {
+	if ft, ok := f.match("read", name); ok {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: ft.err}
+	}
+
+	return f.FS.ReadFile(name)
+}
+
+func (f *faultFS) OpenFile(name string, flag int, perm fs.FileMode) (File, error) {
+	if ft, ok := f.match("open", name); ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: ft.err}
+	}
+
+	w, err := f.FS.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+
+	return &faultFile{File: w, fs: f, name: name}, nil
+}
+
+func (f *faultFS) Rename(oldpath string, newpath string) error {
+	if ft, ok := f.match("rename", newpath); ok {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: ft.err}
+	}
+
+	return f.FS.Rename(oldpath, newpath)
+}
+
+type faultFile struct {
+	File
+	fs   *faultFS
+	name string
+}
+
+func (f *faultFile) Write(p []byte) (int, error) {
+	ft, ok := f.fs.match("write", f.name)
+	if !ok {
+		return f.File.Write(p)
+	}
+
+	n, err := f.File.Write(p[:min(ft.partial, len(p))])
+	if err != nil {
+		return n, err
+	}
+
+	if ft.err != nil {
+		return n, &fs.PathError{Op: "write", Path: f.name, Err: ft.err}
+	}
+
+	return n, nil
+}
+
+func (f *faultFile) Sync() error {
+	if ft, ok := f.fs.match("sync", f.name); ok {
+		return &fs.PathError{Op: "sync", Path: f.name, Err: ft.err}
+	}
+
+	return f.File.Sync()
+}
+
+func recovered(fn func()) (err error) {
+	defer func() {
+		err, _ = recover().(error)
+	}()
+
+	fn()
+	return nil
+}
+
+func TestFile_MemFS(t *testing.T) {
+	tr := tracker{m: &sync.Mutex{}, db: make(map[persistence.Section]string), opts: []Option{WithFS(NewMemFS())}}
+	test.Impl{New: tr.New, Switch: tr.Switch, Done: tr.Done}.Test(t)
+}
+
+func TestMemFS(t *testing.T) {
+	t.Run("written files can be read, listed and renamed", func(t *testing.T) {
+		fsys := NewMemFS()
+
+		assert.NoError(t, fsys.MkdirAll("/a/b", 0700))
+
+		w, err := fsys.OpenFile("/a/b/file", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		assert.NoError(t, err)
+		_, err = w.Write([]byte("data"))
+		assert.NoError(t, err)
+		assert.NoError(t, w.Close())
+
+		entries, err := fsys.ReadDir("/a/b")
+		assert.NoError(t, err)
+		assert.Len(t, entries, 1)
+		assert.Equal(t, "file", entries[0].Name())
+
+		assert.NoError(t, fsys.Rename("/a", "/c"))
+
+		b, err := fsys.ReadFile("/c/b/file")
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("data"), b)
+
+		_, err = fsys.Stat("/a/b/file")
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+	})
+
+	t.Run("files cannot be created in missing directories", func(t *testing.T) {
+		fsys := NewMemFS()
+
+		_, err := fsys.OpenFile("/missing/file", os.O_WRONLY|os.O_CREATE, 0600)
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+	})
+
+	t.Run("non empty directories cannot be removed or replaced", func(t *testing.T) {
+		fsys := NewMemFS()
+
+		assert.NoError(t, fsys.MkdirAll("/a/child", 0700))
+		assert.NoError(t, fsys.MkdirAll("/b/child", 0700))
+
+		assert.Error(t, fsys.Remove("/a"))
+		assert.Error(t, fsys.Rename("/a", "/b"))
+
+		assert.NoError(t, fsys.RemoveAll("/a"))
+
+		_, err := fsys.Stat("/a/child")
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+	})
+
+	t.Run("linked files survive removal of the original", func(t *testing.T) {
+		fsys := NewMemFS()
+
+		w, err := fsys.OpenFile("/file", os.O_WRONLY|os.O_CREATE, 0600)
+		assert.NoError(t, err)
+		_, _ = w.Write([]byte("data"))
+		_ = w.Close()
+
+		assert.NoError(t, fsys.Link("/file", "/link"))
+		assert.NoError(t, fsys.Remove("/file"))
+
+		b, err := fsys.ReadFile("/link")
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("data"), b)
+	})
+}
+
+func TestFile_Faults(t *testing.T) {
+	const dir = "/store"
+
+	setup := func(t *testing.T) (*faultFS, *file) {
+		fsys := newFaultFS(NewMemFS())
+
+		f := New(dir, WithFS(fsys)).(*file)
+		f.Set("key", "original")
+		f.Section("sub").Set("key", int64(1))
+		f.Sync()
+
+		return fsys, f
+	}
+
+	reopen := func(t *testing.T, fsys FS) persistence.Section {
+		f := New(dir, WithFS(fsys)).(*file)
+		t.Cleanup(func() { stopDirtyTimers(f) })
+		return f
+	}
+
+	assertIntact := func(t *testing.T, fsys *faultFS) {
+		fsys.clear()
+
+		v, _ := reopen(t, fsys).String("key")
+		assert.Equal(t, "original", v)
+
+		_, err := fsys.Stat(filepath.Join(dir, "data.json.tmp"))
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+	}
+
+	t.Run("no space left during write leaves previous data intact", func(t *testing.T) {
+		fsys, f := setup(t)
+		defer stopDirtyTimers(f)
+
+		fsys.inject(fault{op: "write", suffix: "data.json.tmp", err: syscall.ENOSPC})
+
+		f.Set("key", "updated")
+		err := recovered(f.Sync)
+
+		assert.ErrorIs(t, err, syscall.ENOSPC)
+		assertIntact(t, fsys)
+	})
+
+	t.Run("partial write with an error leaves previous data intact", func(t *testing.T) {
+		fsys, f := setup(t)
+		defer stopDirtyTimers(f)
+
+		fsys.inject(fault{op: "write", suffix: "data.json.tmp", err: syscall.ENOSPC, partial: 5})
+
+		f.Set("key", "updated")
+		err := recovered(f.Sync)
+
+		assert.ErrorIs(t, err, syscall.ENOSPC)
+		assertIntact(t, fsys)
+	})
+
+	t.Run("short write without an error is detected", func(t *testing.T) {
+		fsys, f := setup(t)
+		defer stopDirtyTimers(f)
+
+		fsys.inject(fault{op: "write", suffix: "data.json.tmp", partial: 5})
+
+		f.Set("key", "updated")
+		err := recovered(f.Sync)
+
+		assert.ErrorIs(t, err, io.ErrShortWrite)
+		assertIntact(t, fsys)
+	})
+
+	t.Run("failure to sync leaves previous data intact", func(t *testing.T) {
+		fsys, f := setup(t)
+		defer stopDirtyTimers(f)
+
+		fsys.inject(fault{op: "sync", suffix: "data.json.tmp", err: syscall.EIO})
+
+		f.Set("key", "updated")
+		err := recovered(f.Sync)
+
+		assert.ErrorIs(t, err, syscall.EIO)
+		assertIntact(t, fsys)
+	})
+
+	t.Run("failure to replace the data file keeps it when rotating generations", func(t *testing.T) {
+		fsys := newFaultFS(NewMemFS())
+
+		f := New(dir, WithFS(fsys), FallbackToPrevious()).(*file)
+		defer stopDirtyTimers(f)
+
+		f.Set("key", "original")
+		f.Sync()
+
+		fsys.inject(fault{op: "rename", suffix: "data.json", err: syscall.EIO})
+
+		f.Set("key", "updated")
+		err := recovered(f.Sync)
+
+		assert.ErrorIs(t, err, syscall.EIO)
+		assertIntact(t, fsys)
+	})
+
+	t.Run("failure to write a checksum is surfaced", func(t *testing.T) {
+		fsys, f := setup(t)
+		defer stopDirtyTimers(f)
+
+		fsys.inject(fault{op: "write", suffix: "data.json.sum.tmp", err: syscall.ENOSPC})
+
+		f.Set("key", "updated")
+		err := recovered(f.Sync)
+
+		assert.ErrorIs(t, err, syscall.ENOSPC)
+	})
+
+	t.Run("io error on read is surfaced", func(t *testing.T) {
+		fsys, f := setup(t)
+		stopDirtyTimers(f)
+
+		fsys.inject(fault{op: "read", suffix: filepath.Join("sub", "data.json"), err: syscall.EIO})
+
+		r := reopen(t, fsys)
+
+		v, _ := r.String("key")
+		assert.Equal(t, "original", v)
+
+		err := recovered(func() {
+			r.Section("sub").Int("key")
+		})
+
+		assert.ErrorIs(t, err, syscall.EIO)
+		assert.False(t, errors.Is(err, fs.ErrNotExist))
+	})
+
+	t.Run("io error on a sidecar is surfaced and keeps the sidecar", func(t *testing.T) {
+		fsys := newFaultFS(NewMemFS())
+		large := []byte("a value over the sidecar threshold")
+		sidecar := filepath.Join(dir, sidecarName(large)+sidecarSuffix)
+
+		f := New(dir, WithFS(fsys), SidecarThreshold(8)).(*file)
+		f.Set("large", large)
+		f.Sync()
+		stopDirtyTimers(f)
+
+		fsys.inject(fault{op: "read", suffix: sidecarSuffix, err: syscall.EIO})
+
+		r := New(dir, WithFS(fsys), SidecarThreshold(8)).(*file)
+		defer stopDirtyTimers(r)
+
+		err := recovered(func() { r.Bytes("large") })
+		assert.ErrorIs(t, err, syscall.EIO)
+
+		fsys.clear()
+
+		v, _ := r.Bytes("large")
+		assert.Equal(t, large, v)
+
+		r.Sync()
+
+		_, err = fsys.Stat(sidecar)
+		assert.NoError(t, err)
+	})
+
+	t.Run("io error on read recovers once the fault clears", func(t *testing.T) {
+		fsys, f := setup(t)
+		stopDirtyTimers(f)
+
+		fsys.inject(fault{op: "read", suffix: "data.json", err: syscall.EIO})
+
+		r := reopen(t, fsys)
+		assert.Error(t, recovered(func() { r.String("key") }))
+
+		fsys.clear()
+
+		v, _ := r.String("key")
+		assert.Equal(t, "original", v)
+	})
+}
@@ -0,0 +1,64 @@
+package file
+
+import (
+	"bytes"
+	"errors"
+	"github.com/shimmeringbee/persistence"
+	"io/fs"
+	"path"
+)
+
+func LoadFS(fsys fs.FS, dir string, dst persistence.Section, opts ...Option) error {
+	o := defaultOptions()
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return loadFS(fsys, dir, dst, o)
+}
+
+func loadFS(fsys fs.FS, dir string, dst persistence.Section, opts *options) error {
+	read := func(name string) ([]byte, error) {
+		return fs.ReadFile(fsys, name)
+	}
+
+	dataPath := func(c Codec) string {
+		return path.Join(dir, dataFilePrefix+c.Extension())
+	}
+
+	readSidecarFS := func(name string) ([]byte, error) {
+		return readSidecar(read, path.Join(dir, name+sidecarSuffix), name)
+	}
+
+	codec, b, _, err := readData(read, dataPath, opts)
+	if err == nil {
+		d, err := codec.Decode(bytes.NewReader(b))
+		if err != nil {
+			return err
+		}
+
+		for k, v := range d {
+			if err := loadValue(dst, k, v, readSidecarFS, opts.coerce); err != nil && !sidecarLost(err) {
+				return err
+			}
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return err
+	}
+
+	for _, ent := range entries {
+		if ent.IsDir() {
+			if err := loadFS(fsys, path.Join(dir, ent.Name()), dst.Section(ent.Name()), opts); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
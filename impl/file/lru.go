@@ -0,0 +1,57 @@
+package file
+
+import (
+	"container/list"
+	"sync"
+)
+
+func newLRU(max int) *lru {
+	return &lru{m: &sync.Mutex{}, max: max, order: list.New(), items: make(map[*file]*list.Element)}
+}
+
+type lru struct {
+	m     *sync.Mutex
+	max   int
+	order *list.List
+	items map[*file]*list.Element
+}
+
+func (l *lru) touch(f *file) {
+	if l.max <= 0 {
+		return
+	}
+
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	if e, ok := l.items[f]; ok {
+		l.order.MoveToFront(e)
+	} else {
+		l.items[f] = l.order.PushFront(f)
+	}
+
+	for e := l.order.Back(); e != nil && l.order.Len() > l.max; {
+		prev := e.Prev()
+
+		if c := e.Value.(*file); c != f && c.evict() {
+			l.order.Remove(e)
+			delete(l.items, c)
+		}
+
+		e = prev
+	}
+}
+
+func (l *lru) remove(f *file) {
+	if l.max <= 0 {
+		return
+	}
+
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	if e, ok := l.items[f]; ok {
+		l.order.Remove(e)
+		delete(l.items, f)
+	}
+}
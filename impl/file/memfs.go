@@ -0,0 +1,392 @@
+package file
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+func NewMemFS() FS {
+	return &memFS{m: &sync.Mutex{}, nodes: make(map[string]*memNode)}
+}
+
+type memFS struct {
+	m     *sync.Mutex
+	nodes map[string]*memNode
+}
+
+type memNode struct {
+	dir     bool
+	data    []byte
+	modTime time.Time
+}
+
+var _ FS = (*memFS)(nil)
+
+func isRoot(name string) bool {
+	return filepath.Dir(name) == name
+}
+
+func within(name string, dir string) bool {
+	rel, err := filepath.Rel(dir, name)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func (m *memFS) lookup(name string) (*memNode, bool) {
+	if isRoot(name) {
+		return &memNode{dir: true}, true
+	}
+
+	n, ok := m.nodes[name]
+	return n, ok
+}
+
+func (m *memFS) parentDir(op string, name string) error {
+	parent, ok := m.lookup(filepath.Dir(name))
+	if !ok {
+		return &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+
+	if !parent.dir {
+		return &fs.PathError{Op: op, Path: name, Err: syscall.ENOTDIR}
+	}
+
+	return nil
+}
+
+func (m *memFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	name = filepath.Clean(name)
+
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	n, ok := m.lookup(name)
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	if !n.dir {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: syscall.ENOTDIR}
+	}
+
+	var entries []fs.DirEntry
+
+	for p, child := range m.nodes {
+		if p != name && filepath.Dir(p) == name {
+			entries = append(entries, fs.FileInfoToDirEntry(child.info(filepath.Base(p))))
+		}
+	}
+
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+
+	return entries, nil
+}
+
+func (m *memFS) ReadFile(name string) ([]byte, error) {
+	name = filepath.Clean(name)
+
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	n, ok := m.lookup(name)
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	if n.dir {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: syscall.EISDIR}
+	}
+
+	return slices.Clone(n.data), nil
+}
+
+func (m *memFS) Stat(name string) (fs.FileInfo, error) {
+	name = filepath.Clean(name)
+
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	n, ok := m.lookup(name)
+	if !ok {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return n.info(filepath.Base(name)), nil
+}
+
+func (m *memFS) OpenFile(name string, flag int, _ fs.FileMode) (File, error) {
+	name = filepath.Clean(name)
+
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	if err := m.parentDir("open", name); err != nil {
+		return nil, err
+	}
+
+	n, ok := m.lookup(name)
+
+	switch {
+	case ok && n.dir:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: syscall.EISDIR}
+	case ok && flag&os.O_CREATE != 0 && flag&os.O_EXCL != 0:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrExist}
+	case ok && flag&os.O_TRUNC != 0:
+		n.data = nil
+		n.modTime = time.Now()
+	case !ok && flag&os.O_CREATE == 0:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	case !ok:
+		n = &memNode{modTime: time.Now()}
+		m.nodes[name] = n
+	}
+
+	return &memFile{fs: m, name: name, node: n}, nil
+}
+
+func (m *memFS) MkdirAll(name string, _ fs.FileMode) error {
+	name = filepath.Clean(name)
+
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	var missing []string
+
+	for p := name; !isRoot(p); p = filepath.Dir(p) {
+		if n, ok := m.nodes[p]; ok {
+			if !n.dir {
+				return &fs.PathError{Op: "mkdir", Path: p, Err: syscall.ENOTDIR}
+			}
+
+			break
+		}
+
+		missing = append(missing, p)
+	}
+
+	for _, p := range missing {
+		m.nodes[p] = &memNode{dir: true, modTime: time.Now()}
+	}
+
+	return nil
+}
+
+func (m *memFS) Rename(oldpath string, newpath string) error {
+	oldpath, newpath = filepath.Clean(oldpath), filepath.Clean(newpath)
+
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	linkErr := func(err error) error {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: err}
+	}
+
+	n, ok := m.nodes[oldpath]
+	if !ok {
+		return linkErr(fs.ErrNotExist)
+	}
+
+	if oldpath == newpath {
+		return nil
+	}
+
+	if err := m.parentDir("rename", newpath); err != nil {
+		return linkErr(fs.ErrNotExist)
+	}
+
+	existing, exists := m.lookup(newpath)
+
+	if !n.dir {
+		if exists && existing.dir {
+			return linkErr(syscall.EISDIR)
+		}
+
+		m.nodes[newpath] = n
+		delete(m.nodes, oldpath)
+		return nil
+	}
+
+	if within(newpath, oldpath) {
+		return linkErr(syscall.EINVAL)
+	}
+
+	if exists {
+		if !existing.dir {
+			return linkErr(syscall.ENOTDIR)
+		}
+
+		if m.hasChildren(newpath) {
+			return linkErr(syscall.ENOTEMPTY)
+		}
+	}
+
+	for p, child := range m.nodes {
+		if within(p, oldpath) {
+			rel, _ := filepath.Rel(oldpath, p)
+			m.nodes[filepath.Join(newpath, rel)] = child
+			delete(m.nodes, p)
+		}
+	}
+
+	m.nodes[newpath] = n
+	delete(m.nodes, oldpath)
+	return nil
+}
+
+func (m *memFS) hasChildren(dir string) bool {
+	for p := range m.nodes {
+		if p != dir && filepath.Dir(p) == dir {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *memFS) Remove(name string) error {
+	name = filepath.Clean(name)
+
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	n, ok := m.nodes[name]
+	if !ok {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+
+	if n.dir && m.hasChildren(name) {
+		return &fs.PathError{Op: "remove", Path: name, Err: syscall.ENOTEMPTY}
+	}
+
+	delete(m.nodes, name)
+	return nil
+}
+
+func (m *memFS) RemoveAll(name string) error {
+	name = filepath.Clean(name)
+
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	for p := range m.nodes {
+		if p == name || within(p, name) {
+			delete(m.nodes, p)
+		}
+	}
+
+	return nil
+}
+
+func (m *memFS) Link(oldname string, newname string) error {
+	oldname, newname = filepath.Clean(oldname), filepath.Clean(newname)
+
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	linkErr := func(err error) error {
+		return &os.LinkError{Op: "link", Old: oldname, New: newname, Err: err}
+	}
+
+	n, ok := m.nodes[oldname]
+	if !ok {
+		return linkErr(fs.ErrNotExist)
+	}
+
+	if n.dir {
+		return linkErr(syscall.EPERM)
+	}
+
+	if _, exists := m.lookup(newname); exists {
+		return linkErr(fs.ErrExist)
+	}
+
+	if err := m.parentDir("link", newname); err != nil {
+		return linkErr(fs.ErrNotExist)
+	}
+
+	m.nodes[newname] = n
+	return nil
+}
+
+func (n *memNode) info(name string) fs.FileInfo {
+	return memInfo{name: name, dir: n.dir, size: int64(len(n.data)), modTime: n.modTime}
+}
+
+type memInfo struct {
+	name    string
+	dir     bool
+	size    int64
+	modTime time.Time
+}
+
+func (i memInfo) Name() string {
+	return i.name
+}
+
+func (i memInfo) Size() int64 {
+	return i.size
+}
+
+func (i memInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0700
+	}
+
+	return 0600
+}
+
+func (i memInfo) ModTime() time.Time {
+	return i.modTime
+}
+
+func (i memInfo) IsDir() bool {
+	return i.dir
+}
+
+func (i memInfo) Sys() any {
+	return nil
+}
+
+type memFile struct {
+	fs     *memFS
+	name   string
+	node   *memNode
+	closed bool
+}
+
+func (f *memFile) Write(p []byte) (int, error) {
+	f.fs.m.Lock()
+	defer f.fs.m.Unlock()
+
+	if f.closed {
+		return 0, &fs.PathError{Op: "write", Path: f.name, Err: fs.ErrClosed}
+	}
+
+	f.node.data = append(f.node.data, p...)
+	f.node.modTime = time.Now()
+
+	return len(p), nil
+}
+
+func (f *memFile) Sync() error {
+	return nil
+}
+
+func (f *memFile) Close() error {
+	f.fs.m.Lock()
+	defer f.fs.m.Unlock()
+
+	if f.closed {
+		return &fs.PathError{Op: "close", Path: f.name, Err: fs.ErrClosed}
+	}
+
+	f.closed = true
+	return nil
+}
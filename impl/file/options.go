@@ -0,0 +1,118 @@
+package file
+
+import (
+	"context"
+	"time"
+)
+
+type Option func(*options)
+
+const DefaultSidecarThreshold = 64 * 1024
+
+type options struct {
+	readOnly          bool
+	preload           bool
+	maxLoadedSections int
+	sidecarThreshold  int
+	codec             Codec
+	fs                FS
+
+	checksum           ChecksumAlgorithm
+	onChecksumMismatch func(error)
+	fallbackToPrevious bool
+
+	backupDir         string
+	backupGenerations int
+
+	watchCtx         context.Context
+	watchInterval    time.Duration
+	onExternalChange func(ExternalChange)
+
+	coerce  bool
+	onLossy func(error)
+}
+
+func defaultOptions() *options {
+	return &options{sidecarThreshold: DefaultSidecarThreshold, codec: JSON, checksum: CRC32C, fs: OS}
+}
+
+func ReadOnly() Option {
+	return func(o *options) {
+		o.readOnly = true
+	}
+}
+
+func Preload() Option {
+	return func(o *options) {
+		o.preload = true
+	}
+}
+
+func MaxLoadedSections(n int) Option {
+	return func(o *options) {
+		o.maxLoadedSections = n
+	}
+}
+
+func SidecarThreshold(n int) Option {
+	return func(o *options) {
+		o.sidecarThreshold = n
+	}
+}
+
+func WithCodec(c Codec) Option {
+	return func(o *options) {
+		o.codec = c
+	}
+}
+
+func WithFS(fsys FS) Option {
+	return func(o *options) {
+		o.fs = fsys
+	}
+}
+
+func Checksum(a ChecksumAlgorithm) Option {
+	return func(o *options) {
+		o.checksum = a
+	}
+}
+
+func OnChecksumMismatch(fn func(error)) Option {
+	return func(o *options) {
+		o.onChecksumMismatch = fn
+	}
+}
+
+func FallbackToPrevious() Option {
+	return func(o *options) {
+		o.fallbackToPrevious = true
+	}
+}
+
+func Backups(dir string, generations int) Option {
+	return func(o *options) {
+		o.backupDir = dir
+		o.backupGenerations = generations
+	}
+}
+
+func WatchChanges(ctx context.Context, interval time.Duration) Option {
+	return func(o *options) {
+		o.watchCtx = ctx
+		o.watchInterval = interval
+	}
+}
+
+func OnExternalChange(fn func(ExternalChange)) Option {
+	return func(o *options) {
+		o.onExternalChange = fn
+	}
+}
+
+func Coerce(onLossy func(error)) Option {
+	return func(o *options) {
+		o.coerce = true
+		o.onLossy = onLossy
+	}
+}
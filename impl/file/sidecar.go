@@ -0,0 +1,103 @@
+package file
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/shimmeringbee/persistence"
+	"io/fs"
+	"strings"
+)
+
+const sidecarSuffix = ".bin"
+
+var ErrSidecarMismatch = errors.New("sidecar content does not match hash")
+
+func sidecarName(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func (f *file) sidecarPath(name string) string {
+	return fmt.Sprintf("%s%s%s", f.dir, name, sidecarSuffix)
+}
+
+func (f *file) readSidecar(name string) ([]byte, error) {
+	return readSidecar(f.store.opts.fs.ReadFile, f.sidecarPath(name), name)
+}
+
+func readSidecar(read readFunc, path string, name string) ([]byte, error) {
+	data, err := read(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if sidecarName(data) != name {
+		return nil, fmt.Errorf("%s: %w", path, ErrSidecarMismatch)
+	}
+
+	return data, nil
+}
+
+func sidecarLost(err error) bool {
+	return errors.Is(err, ErrSidecarMismatch) || errors.Is(err, fs.ErrNotExist)
+}
+
+func (f *file) writeSidecar(data []byte) (string, error) {
+	name := sidecarName(data)
+	path := f.sidecarPath(name)
+
+	if _, err := f.store.opts.fs.Stat(path); err == nil {
+		return name, nil
+	}
+
+	return name, writeFileAtomic(f.store.opts.fs, path, data)
+}
+
+func referencedSidecars(read readFunc, codec Codec, path string, used map[string]struct{}) error {
+	b, err := read(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+
+		return err
+	}
+
+	d, err := codec.Decode(bytes.NewReader(b))
+	if err != nil {
+		return nil
+	}
+
+	for _, v := range d {
+		if name, ok := v.Value.(string); ok && v.Type == persistence.Bytes && v.Encoding == encodingSidecar {
+			used[name] = struct{}{}
+		}
+	}
+
+	return nil
+}
+
+func (f *file) removeUnusedSidecars(used map[string]struct{}) error {
+	entries, err := f.store.opts.fs.ReadDir(f.dir)
+	if err != nil {
+		return err
+	}
+
+	for _, ent := range entries {
+		name, isSidecar := strings.CutSuffix(ent.Name(), sidecarSuffix)
+		if ent.IsDir() || !isSidecar {
+			continue
+		}
+
+		if _, ok := used[name]; !ok {
+			if err := f.store.opts.fs.Remove(f.sidecarPath(name)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,227 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/shimmeringbee/persistence"
+	"github.com/shimmeringbee/persistence/diff"
+	"github.com/shimmeringbee/persistence/impl/memory"
+	"io/fs"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+type ExternalChangeKind uint8
+
+const (
+	Reloaded   ExternalChangeKind = 0
+	Merged     ExternalChangeKind = 1
+	Unreadable ExternalChangeKind = 2
+)
+
+func (k ExternalChangeKind) String() string {
+	switch k {
+	case Reloaded:
+		return "reloaded"
+	case Merged:
+		return "merged"
+	case Unreadable:
+		return "unreadable"
+	default:
+		return "unknown"
+	}
+}
+
+type ExternalChange struct {
+	Kind      ExternalChangeKind
+	Dir       string
+	Conflicts []diff.Conflict
+	Preserved string
+	Err       error
+}
+
+const externalSuffix = ".external-"
+
+type stamp struct {
+	path    string
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+func (s stamp) equal(o stamp) bool {
+	return s.path == o.path && s.exists == o.exists && s.size == o.size && s.modTime.Equal(o.modTime)
+}
+
+type watchState struct {
+	m      *sync.Mutex
+	codec  Codec
+	stamp  stamp
+	failed stamp
+	base   persistence.Section
+}
+
+func (f *file) watching() bool {
+	return f.store.opts.watchInterval > 0
+}
+
+func (f *file) statData(path string) (stamp, error) {
+	st := stamp{path: path}
+
+	info, err := f.store.opts.fs.Stat(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return st, nil
+		}
+
+		return st, err
+	}
+
+	st.exists, st.size, st.modTime = true, info.Size(), info.ModTime()
+	return st, nil
+}
+
+func (f *file) recordOnDisk(codec Codec, base persistence.Section) error {
+	st, err := f.statData(f.dataPath(codec))
+	if err != nil {
+		return err
+	}
+
+	f.watch.m.Lock()
+	defer f.watch.m.Unlock()
+
+	f.watch.codec, f.watch.stamp, f.watch.failed, f.watch.base = codec, st, stamp{}, base
+	return nil
+}
+
+func (f *file) watchChanges(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			f.checkChanges()
+		}
+	}
+}
+
+func (f *file) checkChanges() {
+	if change := f.reconcile(false); change != nil {
+		f.notify(*change)
+	}
+
+	f.m.RLock()
+	sections := slices.Collect(maps.Values(f.sections))
+	f.m.RUnlock()
+
+	for _, s := range sections {
+		s.checkChanges()
+	}
+}
+
+func (f *file) notify(change ExternalChange) {
+	if fn := f.store.opts.onExternalChange; fn != nil {
+		fn(change)
+	}
+}
+
+func (f *file) reconcile(syncing bool) *ExternalChange {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	if f.cache == nil {
+		return nil
+	}
+
+	w := f.watch
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	if w.base == nil {
+		return nil
+	}
+
+	current, err := f.statData(w.stamp.path)
+	if err != nil || current.equal(w.stamp) || (!syncing && current.equal(w.failed)) {
+		return nil
+	}
+
+	external, stale, err := f.readExternal(w.codec, current)
+	if err != nil {
+		change := &ExternalChange{Kind: Unreadable, Dir: f.dir, Err: err}
+		w.failed = current
+
+		if syncing {
+			preserved := fmt.Sprintf("%s%s%s", current.path, externalSuffix, time.Now().UTC().Format(generationFormat))
+
+			if err := f.store.opts.fs.Rename(current.path, preserved); err != nil {
+				change.Err = errors.Join(change.Err, err)
+			} else {
+				change.Preserved = preserved
+				w.stamp = stamp{path: current.path}
+			}
+		}
+
+		return change
+	}
+
+	change := &ExternalChange{Kind: Reloaded, Dir: f.dir}
+
+	cache := memory.New()
+	persistence.Copy(cache, external)
+
+	if local := diff.Compute(w.base, f.cache); len(local) > 0 {
+		change.Kind = Merged
+		change.Conflicts = diff.Apply(cache, local)
+		stale = true
+	}
+
+	f.cache = cache
+	w.stamp, w.failed, w.base = current, stamp{}, external
+
+	if stale && !f.store.opts.readOnly {
+		f.modified.Store(true)
+
+		if !syncing {
+			f.dirtyLocked()
+		}
+	}
+
+	return change
+}
+
+func (f *file) readExternal(codec Codec, current stamp) (persistence.Section, bool, error) {
+	external := memory.New()
+
+	if !current.exists {
+		return external, false, nil
+	}
+
+	read := f.store.opts.fs.ReadFile
+
+	b, err := read(current.path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	d, err := codec.Decode(bytes.NewReader(b))
+	if err != nil {
+		return nil, false, err
+	}
+
+	for k, v := range d {
+		if err := loadValue(external, k, v, f.readSidecar, f.store.opts.coerce); err != nil && !sidecarLost(err) {
+			return nil, false, err
+		}
+	}
+
+	stale := verifyChecksum(read, current.path, b) != nil
+	return external, stale, nil
+}
@@ -0,0 +1,198 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"github.com/shimmeringbee/persistence"
+	"github.com/shimmeringbee/persistence/impl/test"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFile_Watching(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tr := tracker{m: &sync.Mutex{}, db: make(map[persistence.Section]string), opts: []Option{WatchChanges(ctx, time.Hour)}}
+	test.Impl{New: tr.New, Switch: tr.Switch, Done: tr.Done}.Test(t)
+}
+
+func TestFile_WatchChanges(t *testing.T) {
+	type recorder struct {
+		m       *sync.Mutex
+		changes []ExternalChange
+	}
+
+	record := func(r *recorder) Option {
+		return OnExternalChange(func(c ExternalChange) {
+			r.m.Lock()
+			defer r.m.Unlock()
+
+			r.changes = append(r.changes, c)
+		})
+	}
+
+	open := func(t *testing.T, dir string, interval time.Duration) (*file, *recorder) {
+		ctx, cancel := context.WithCancel(context.Background())
+		r := &recorder{m: &sync.Mutex{}}
+
+		f := New(dir, WatchChanges(ctx, interval), record(r)).(*file)
+
+		t.Cleanup(func() {
+			cancel()
+			stopDirtyTimers(f)
+		})
+
+		return f, r
+	}
+
+	edit := func(t *testing.T, dir string, values map[string]Value) {
+		buf := &bytes.Buffer{}
+		assert.NoError(t, JSON.Encode(buf, values))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "data.json"), buf.Bytes(), 0600))
+	}
+
+	reopen := func(t *testing.T, dir string) persistence.Section {
+		f := New(dir).(*file)
+		t.Cleanup(func() { stopDirtyTimers(f) })
+		return f
+	}
+
+	t.Run("external edits are reloaded when there are no local changes", func(t *testing.T) {
+		dir := t.TempDir()
+
+		f, r := open(t, dir, time.Hour)
+		f.Set("key", "original")
+		f.Sync()
+
+		edit(t, dir, map[string]Value{"key": {Value: "edited", Type: persistence.String}})
+		f.checkChanges()
+
+		v, _ := f.String("key")
+		assert.Equal(t, "edited", v)
+
+		assert.Len(t, r.changes, 1)
+		assert.Equal(t, Reloaded, r.changes[0].Kind)
+
+		f.Sync()
+
+		v, _ = reopen(t, dir).String("key")
+		assert.Equal(t, "edited", v)
+	})
+
+	t.Run("local and external changes to different keys are merged", func(t *testing.T) {
+		dir := t.TempDir()
+
+		f, r := open(t, dir, time.Hour)
+		f.Set("key", "original")
+		f.Sync()
+
+		f.Set("local", "value")
+		edit(t, dir, map[string]Value{"key": {Value: "edited", Type: persistence.String}})
+		f.checkChanges()
+
+		assert.Len(t, r.changes, 1)
+		assert.Equal(t, Merged, r.changes[0].Kind)
+		assert.Empty(t, r.changes[0].Conflicts)
+
+		f.Sync()
+
+		s := reopen(t, dir)
+
+		v, _ := s.String("key")
+		assert.Equal(t, "edited", v)
+
+		v, _ = s.String("local")
+		assert.Equal(t, "value", v)
+	})
+
+	t.Run("conflicting changes keep the external edit and report the local change", func(t *testing.T) {
+		dir := t.TempDir()
+
+		f, r := open(t, dir, time.Hour)
+		f.Set("key", "original")
+		f.Sync()
+
+		f.Set("key", "local")
+		edit(t, dir, map[string]Value{"key": {Value: "edited", Type: persistence.String}})
+		f.checkChanges()
+
+		v, _ := f.String("key")
+		assert.Equal(t, "edited", v)
+
+		assert.Len(t, r.changes, 1)
+		assert.Equal(t, Merged, r.changes[0].Kind)
+		assert.Len(t, r.changes[0].Conflicts, 1)
+		assert.Equal(t, "key", r.changes[0].Conflicts[0].Change.Path)
+		assert.Equal(t, "local", r.changes[0].Conflicts[0].Change.NewValue)
+	})
+
+	t.Run("sync merges external edits that have not been polled yet", func(t *testing.T) {
+		dir := t.TempDir()
+
+		f, r := open(t, dir, time.Hour)
+		f.Set("key", "original")
+		f.Sync()
+
+		f.Set("local", "value")
+		edit(t, dir, map[string]Value{"key": {Value: "edited", Type: persistence.String}})
+		f.Sync()
+
+		assert.Len(t, r.changes, 1)
+
+		v, _ := reopen(t, dir).String("key")
+		assert.Equal(t, "edited", v)
+	})
+
+	t.Run("unreadable edits are reported once and preserved before being overwritten", func(t *testing.T) {
+		dir := t.TempDir()
+
+		f, r := open(t, dir, time.Hour)
+		f.Set("key", "original")
+		f.Sync()
+
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "data.json"), []byte("{ broken"), 0600))
+
+		f.checkChanges()
+		f.checkChanges()
+
+		assert.Len(t, r.changes, 1)
+		assert.Equal(t, Unreadable, r.changes[0].Kind)
+		assert.Error(t, r.changes[0].Err)
+		assert.Empty(t, r.changes[0].Preserved)
+
+		v, _ := f.String("key")
+		assert.Equal(t, "original", v)
+
+		f.Sync()
+
+		assert.Len(t, r.changes, 2)
+		assert.NotEmpty(t, r.changes[1].Preserved)
+
+		preserved, err := os.ReadFile(r.changes[1].Preserved)
+		assert.NoError(t, err)
+		assert.Equal(t, "{ broken", string(preserved))
+
+		v, _ = reopen(t, dir).String("key")
+		assert.Equal(t, "original", v)
+	})
+
+	t.Run("changes are detected by polling in the background", func(t *testing.T) {
+		dir := t.TempDir()
+
+		f, _ := open(t, dir, 10*time.Millisecond)
+		f.Section("sub").Set("key", "original")
+		f.Sync()
+
+		edit(t, filepath.Join(dir, "sub"), map[string]Value{"key": {Value: "edited", Type: persistence.String}})
+
+		assert.Eventually(t, func() bool {
+			v, _ := f.Section("sub").String("key")
+			return v == "edited"
+		}, time.Second, 10*time.Millisecond)
+	})
+}
@@ -1,19 +1,34 @@
 package memory
 
 import (
+	"errors"
 	"fmt"
 	"github.com/shimmeringbee/persistence"
+	"iter"
+	"slices"
+	"strings"
 	"sync"
 )
 
-func New() persistence.Section {
-	return &memory{m: &sync.RWMutex{}, kv: make(map[string]interface{}), sections: make(map[string]persistence.Section)}
+func New(opts ...Option) persistence.Section {
+	o := &options{}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return newMemory(o)
+}
+
+func newMemory(o *options) *memory {
+	return &memory{m: &sync.RWMutex{}, kv: make(map[string]interface{}), sections: make(map[string]persistence.Section), opts: o}
 }
 
 type memory struct {
 	m        *sync.RWMutex
 	kv       map[string]interface{}
 	sections map[string]persistence.Section
+	opts     *options
 }
 
 func (m *memory) Type(key string) persistence.ValueType {
@@ -22,25 +37,61 @@ func (m *memory) Type(key string) persistence.ValueType {
 	m.m.RUnlock()
 
 	if ok {
-		switch v.(type) {
-		case int64:
-			return persistence.Int
-		case uint64:
-			return persistence.UnsignedInt
-		case string:
-			return persistence.String
-		case float64:
-			return persistence.Float
-		case bool:
-			return persistence.Bool
-		case []byte:
-			return persistence.Bytes
-		}
+		return valueType(v)
+	}
+
+	return persistence.None
+}
+
+func valueType(v any) persistence.ValueType {
+	switch v.(type) {
+	case int64:
+		return persistence.Int
+	case uint64:
+		return persistence.UnsignedInt
+	case string:
+		return persistence.String
+	case float64:
+		return persistence.Float
+	case bool:
+		return persistence.Bool
+	case []byte:
+		return persistence.Bytes
 	}
 
 	return persistence.None
 }
 
+func sortedKeys[V any](m *sync.RWMutex, src map[string]V, prefix []string) []string {
+	m.RLock()
+	defer m.RUnlock()
+
+	var keys = make([]string, 0, len(src))
+
+	for k := range src {
+		if hasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+
+	slices.Sort(keys)
+	return keys
+}
+
+func hasPrefix(k string, prefix []string) bool {
+	if len(prefix) == 0 {
+		return true
+	}
+
+	for _, p := range prefix {
+		if strings.HasPrefix(k, p) {
+			return true
+		}
+	}
+
+	return false
+}
+
 var _ persistence.Section = (*memory)(nil)
 
 func (m *memory) SectionExists(key string) bool {
@@ -53,14 +104,20 @@ func (m *memory) SectionExists(key string) bool {
 }
 
 func (m *memory) Section(key ...string) persistence.Section {
+	if len(key) == 0 {
+		panic(fmt.Errorf("section: %w", persistence.ErrNoSectionKey))
+	}
+
 	m.m.RLock()
 	s, ok := m.sections[key[0]]
 	m.m.RUnlock()
 
 	if !ok {
-		s = New()
 		m.m.Lock()
-		m.sections[key[0]] = s
+		if s, ok = m.sections[key[0]]; !ok {
+			s = newMemory(m.opts)
+			m.sections[key[0]] = s
+		}
 		m.m.Unlock()
 	}
 
@@ -71,6 +128,26 @@ func (m *memory) Section(key ...string) persistence.Section {
 	}
 }
 
+func (m *memory) SectionIfExists(key ...string) (persistence.Section, bool) {
+	if len(key) == 0 {
+		return nil, false
+	}
+
+	m.m.RLock()
+	s, ok := m.sections[key[0]]
+	m.m.RUnlock()
+
+	if !ok {
+		return nil, false
+	}
+
+	if len(key) > 1 {
+		return s.SectionIfExists(key[1:]...)
+	} else {
+		return s, true
+	}
+}
+
 func (m *memory) SectionKeys() []string {
 	m.m.RLock()
 	defer m.m.RUnlock()
@@ -84,6 +161,20 @@ func (m *memory) SectionKeys() []string {
 	return keys
 }
 
+func (m *memory) AllSections(prefix ...string) iter.Seq2[string, persistence.Section] {
+	return func(yield func(string, persistence.Section) bool) {
+		for _, k := range sortedKeys(m.m, m.sections, prefix) {
+			m.m.RLock()
+			s, ok := m.sections[k]
+			m.m.RUnlock()
+
+			if ok && !yield(k, s) {
+				return
+			}
+		}
+	}
+}
+
 func (m *memory) SectionDelete(key string) bool {
 	m.m.Lock()
 	defer m.m.Unlock()
@@ -97,6 +188,65 @@ func (m *memory) SectionDelete(key string) bool {
 	return found
 }
 
+var moveLock = &sync.Mutex{}
+
+func (m *memory) SectionRename(key string, newKey string) bool {
+	return m.SectionMove(key, m, newKey)
+}
+
+func (m *memory) SectionMove(key string, dstParent persistence.Section, dstKey string) bool {
+	dst, ok := dstParent.(*memory)
+	if !ok {
+		return persistence.MoveByCopy(m, key, dstParent, dstKey)
+	}
+
+	moveLock.Lock()
+	defer moveLock.Unlock()
+
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	if dst != m {
+		dst.m.Lock()
+		defer dst.m.Unlock()
+	}
+
+	s, found := m.sections[key]
+	if !found {
+		return false
+	}
+
+	if _, exists := dst.sections[dstKey]; exists {
+		return false
+	}
+
+	if sm, ok := s.(*memory); ok && sm.contains(dst) {
+		return false
+	}
+
+	delete(m.sections, key)
+	dst.sections[dstKey] = s
+
+	return true
+}
+
+func (m *memory) contains(target *memory) bool {
+	if m == target {
+		return true
+	}
+
+	m.m.RLock()
+	defer m.m.RUnlock()
+
+	for _, s := range m.sections {
+		if sm, ok := s.(*memory); ok && sm.contains(target) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (m *memory) Exists(key string) bool {
 	m.m.RLock()
 	defer m.m.RUnlock()
@@ -119,16 +269,41 @@ func (m *memory) Keys() []string {
 	return keys
 }
 
+func (m *memory) All(prefix ...string) iter.Seq2[string, persistence.ValueType] {
+	return func(yield func(string, persistence.ValueType) bool) {
+		m.Walk(func(k string, vt persistence.ValueType, _ any) bool {
+			return yield(k, vt)
+		}, prefix...)
+	}
+}
+
+func (m *memory) Walk(fn func(key string, vt persistence.ValueType, value any) bool, prefix ...string) {
+	for _, k := range sortedKeys(m.m, m.kv, prefix) {
+		m.m.RLock()
+		v, ok := m.kv[k]
+		m.m.RUnlock()
+
+		if ok && !fn(k, valueType(v), v) {
+			return
+		}
+	}
+}
+
 func genericRetrieve[T any](m *memory, key string, defValue ...T) (T, bool) {
 	m.m.RLock()
-	defer m.m.RUnlock()
-
 	v, ok := m.kv[key]
+	m.m.RUnlock()
 
 	if ok {
 		if iV, cok := v.(T); cok {
 			return iV, true
 		}
+
+		if m.opts.coerce {
+			if cV, cok := m.coerce(key, v, *new(T)); cok {
+				return cV.(T), true
+			}
+		}
 	}
 
 	if len(defValue) > 0 {
@@ -139,6 +314,28 @@ func genericRetrieve[T any](m *memory, key string, defValue ...T) (T, bool) {
 	}
 }
 
+func (m *memory) coerce(key string, v any, to any) (any, bool) {
+	var c any
+	var err error
+
+	switch to.(type) {
+	case int64:
+		c, err = persistence.ToInt(v)
+	case uint64:
+		c, err = persistence.ToUInt(v)
+	case float64:
+		c, err = persistence.ToFloat(v)
+	default:
+		return nil, false
+	}
+
+	if errors.Is(err, persistence.ErrLossy) && m.opts.onLossy != nil {
+		m.opts.onLossy(fmt.Errorf("coerce %q: %w", key, err))
+	}
+
+	return c, err == nil
+}
+
 func (m *memory) Int(key string, defValue ...int64) (int64, bool) {
 	return genericRetrieve(m, key, defValue...)
 }
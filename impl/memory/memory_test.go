@@ -1,14 +1,55 @@
 package memory
 
 import (
+	"github.com/shimmeringbee/persistence"
 	"github.com/shimmeringbee/persistence/impl/test"
+	"github.com/stretchr/testify/assert"
 	"testing"
 )
 
 func TestMemory(t *testing.T) {
 	test.Impl{
-		New:    New,
+		New:    func() persistence.Section { return New() },
 		Done:   test.EmptyDone,
 		Switch: test.EmptySwitch,
 	}.Test(t)
 }
+
+func TestMemory_Coercing(t *testing.T) {
+	test.Impl{
+		New:    func() persistence.Section { return New(Coerce(nil)) },
+		Done:   test.EmptyDone,
+		Switch: test.EmptySwitch,
+	}.TestCoercing(t)
+}
+
+func TestMemory_Coerce(t *testing.T) {
+	t.Run("lossy conversions are reported", func(t *testing.T) {
+		var reported []error
+
+		s := New(Coerce(func(err error) {
+			reported = append(reported, err)
+		}))
+
+		s.Set("int", -1)
+		s.Set("text", "abc")
+
+		_, found := s.UInt("int")
+		assert.False(t, found)
+
+		_, found = s.Int("text")
+		assert.False(t, found)
+
+		assert.Len(t, reported, 1)
+		assert.ErrorIs(t, reported[0], persistence.ErrLossy)
+		assert.ErrorContains(t, reported[0], `"int"`)
+	})
+
+	t.Run("stores are strict without coercion", func(t *testing.T) {
+		s := New()
+		s.Set("uint", uint(5))
+
+		_, found := s.Int("uint")
+		assert.False(t, found)
+	})
+}
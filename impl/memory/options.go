@@ -0,0 +1,15 @@
+package memory
+
+type Option func(*options)
+
+type options struct {
+	coerce  bool
+	onLossy func(error)
+}
+
+func Coerce(onLossy func(error)) Option {
+	return func(o *options) {
+		o.coerce = true
+		o.onLossy = onLossy
+	}
+}
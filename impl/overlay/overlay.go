@@ -0,0 +1,430 @@
+package overlay
+
+import (
+	"fmt"
+	"github.com/shimmeringbee/persistence"
+	"iter"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const TombstoneSection = ".tombstones"
+
+func New(top persistence.Section, lower ...persistence.Section) persistence.Section {
+	return &overlay{m: &sync.Mutex{}, top: top, lower: lower}
+}
+
+type overlay struct {
+	m      *sync.Mutex
+	parent *overlay
+	key    string
+
+	top   persistence.Section
+	lower []persistence.Section
+}
+
+var _ persistence.Section = (*overlay)(nil)
+var _ persistence.Syncer = (*overlay)(nil)
+
+func (o *overlay) topLayer(create bool) (persistence.Section, bool) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if o.top == nil {
+		parent, ok := o.parent.topLayer(create)
+		if !ok {
+			return nil, false
+		}
+
+		if create {
+			o.top = parent.Section(o.key)
+		} else if s, ok := parent.SectionIfExists(o.key); ok {
+			o.top = s
+		} else {
+			return nil, false
+		}
+	}
+
+	return o.top, true
+}
+
+func (o *overlay) readTop() (persistence.Section, bool) {
+	return o.topLayer(false)
+}
+
+func (o *overlay) writeTop() persistence.Section {
+	t, _ := o.topLayer(true)
+	return t
+}
+
+func (o *overlay) tombstones() (persistence.Section, bool) {
+	if t, ok := o.readTop(); ok {
+		return t.SectionIfExists(TombstoneSection)
+	}
+
+	return nil, false
+}
+
+func (o *overlay) keyDeleted(key string) bool {
+	if t, ok := o.tombstones(); ok {
+		return t.Exists(key)
+	}
+
+	return false
+}
+
+func (o *overlay) sectionDeleted(key string) bool {
+	if t, ok := o.tombstones(); ok {
+		return t.SectionExists(key)
+	}
+
+	return false
+}
+
+func (o *overlay) layerFor(key string) (persistence.Section, bool) {
+	if t, ok := o.readTop(); ok && (t.Exists(key) || o.keyDeleted(key)) {
+		return t, true
+	}
+
+	for _, l := range o.lower {
+		if l.Exists(key) {
+			return l, true
+		}
+	}
+
+	return nil, false
+}
+
+func (o *overlay) lowerExists(key string) bool {
+	for _, l := range o.lower {
+		if l.Exists(key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (o *overlay) lowerSectionExists(key string) bool {
+	if o.sectionDeleted(key) {
+		return false
+	}
+
+	for _, l := range o.lower {
+		if l.SectionExists(key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (o *overlay) child(key string, create bool) (*overlay, bool) {
+	c := &overlay{m: &sync.Mutex{}, parent: o, key: key}
+
+	if create {
+		c.top = o.writeTop().Section(key)
+	} else if t, ok := o.readTop(); ok {
+		c.top, _ = t.SectionIfExists(key)
+	}
+
+	if !o.sectionDeleted(key) {
+		for _, l := range o.lower {
+			if s, ok := l.SectionIfExists(key); ok {
+				c.lower = append(c.lower, s)
+			}
+		}
+	}
+
+	if c.top == nil && len(c.lower) == 0 {
+		return nil, false
+	}
+
+	return c, true
+}
+
+func (o *overlay) Section(key ...string) persistence.Section {
+	if len(key) == 0 {
+		panic(fmt.Errorf("section: %w", persistence.ErrNoSectionKey))
+	}
+
+	s, _ := o.child(key[0], true)
+
+	if len(key) > 1 {
+		return s.Section(key[1:]...)
+	} else {
+		return s
+	}
+}
+
+func (o *overlay) SectionIfExists(key ...string) (persistence.Section, bool) {
+	if len(key) == 0 || key[0] == TombstoneSection {
+		return nil, false
+	}
+
+	s, ok := o.child(key[0], false)
+	if !ok {
+		return nil, false
+	}
+
+	if len(key) > 1 {
+		return s.SectionIfExists(key[1:]...)
+	} else {
+		return s, true
+	}
+}
+
+func (o *overlay) SectionKeys() []string {
+	var keys []string
+
+	if t, ok := o.readTop(); ok {
+		keys = slices.DeleteFunc(t.SectionKeys(), func(k string) bool {
+			return k == TombstoneSection
+		})
+	}
+
+	for _, l := range o.lower {
+		for _, k := range l.SectionKeys() {
+			if !o.sectionDeleted(k) {
+				keys = append(keys, k)
+			}
+		}
+	}
+
+	slices.Sort(keys)
+	return slices.Compact(keys)
+}
+
+func (o *overlay) AllSections(prefix ...string) iter.Seq2[string, persistence.Section] {
+	return func(yield func(string, persistence.Section) bool) {
+		for _, k := range o.SectionKeys() {
+			if !hasPrefix(k, prefix) {
+				continue
+			}
+
+			if s, ok := o.child(k, false); ok && !yield(k, s) {
+				return
+			}
+		}
+	}
+}
+
+func (o *overlay) SectionExists(key string) bool {
+	if key == TombstoneSection {
+		return false
+	}
+
+	if t, ok := o.readTop(); ok && t.SectionExists(key) {
+		return true
+	}
+
+	return o.lowerSectionExists(key)
+}
+
+func (o *overlay) SectionDelete(key string) bool {
+	found := o.SectionExists(key)
+
+	if t, ok := o.readTop(); ok {
+		t.SectionDelete(key)
+	}
+
+	for _, l := range o.lower {
+		if l.SectionExists(key) {
+			o.writeTop().Section(TombstoneSection, key)
+			break
+		}
+	}
+
+	return found
+}
+
+func (o *overlay) SectionRename(key string, newKey string) bool {
+	return o.SectionMove(key, o, newKey)
+}
+
+func (o *overlay) SectionMove(key string, dstParent persistence.Section, dstKey string) bool {
+	dst, ok := dstParent.(*overlay)
+	if !ok {
+		return persistence.MoveByCopy(o, key, dstParent, dstKey)
+	}
+
+	if o.contains(key, dst) {
+		return false
+	}
+
+	t, ok := o.readTop()
+	if !ok {
+		return persistence.MoveByCopy(o, key, dstParent, dstKey)
+	}
+
+	if moving, ok := t.SectionIfExists(key); ok {
+		if dt, ok := dst.readTop(); ok && persistence.Contains(moving, dt) {
+			return false
+		}
+
+		if !o.lowerSectionExists(key) && !dst.SectionExists(dstKey) {
+			return t.SectionMove(key, dst.writeTop(), dstKey)
+		}
+	}
+
+	return persistence.MoveByCopy(o, key, dstParent, dstKey)
+}
+
+func (o *overlay) path() (*overlay, []string) {
+	if o.parent == nil {
+		return o, nil
+	}
+
+	root, p := o.parent.path()
+	return root, append(p, o.key)
+}
+
+func (o *overlay) contains(key string, dst *overlay) bool {
+	root, p := o.path()
+	dstRoot, dp := dst.path()
+
+	p = append(p, key)
+	return root == dstRoot && len(dp) >= len(p) && slices.Equal(dp[:len(p)], p)
+}
+
+func (o *overlay) Keys() []string {
+	var keys []string
+
+	if t, ok := o.readTop(); ok {
+		keys = t.Keys()
+	}
+
+	for _, l := range o.lower {
+		for _, k := range l.Keys() {
+			if !o.keyDeleted(k) {
+				keys = append(keys, k)
+			}
+		}
+	}
+
+	slices.Sort(keys)
+	return slices.Compact(keys)
+}
+
+func (o *overlay) All(prefix ...string) iter.Seq2[string, persistence.ValueType] {
+	return func(yield func(string, persistence.ValueType) bool) {
+		o.Walk(func(k string, vt persistence.ValueType, _ any) bool {
+			return yield(k, vt)
+		}, prefix...)
+	}
+}
+
+func (o *overlay) Walk(fn func(key string, vt persistence.ValueType, value any) bool, prefix ...string) {
+	for _, k := range o.Keys() {
+		if !hasPrefix(k, prefix) {
+			continue
+		}
+
+		l, ok := o.layerFor(k)
+		if !ok {
+			continue
+		}
+
+		if v, vt, found := persistence.Get(l, k); found && !fn(k, vt, v) {
+			return
+		}
+	}
+}
+
+func hasPrefix(k string, prefix []string) bool {
+	if len(prefix) == 0 {
+		return true
+	}
+
+	for _, p := range prefix {
+		if strings.HasPrefix(k, p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (o *overlay) Exists(key string) bool {
+	if l, ok := o.layerFor(key); ok {
+		return l.Exists(key)
+	}
+
+	return false
+}
+
+func (o *overlay) Type(key string) persistence.ValueType {
+	if l, ok := o.layerFor(key); ok {
+		return l.Type(key)
+	}
+
+	return persistence.None
+}
+
+func get[T any](o *overlay, key string, fn func(persistence.Section, string, ...T) (T, bool), defValue ...T) (T, bool) {
+	if l, ok := o.layerFor(key); ok {
+		return fn(l, key, defValue...)
+	}
+
+	if len(defValue) > 0 {
+		return defValue[0], false
+	} else {
+		zero := *new(T)
+		return zero, false
+	}
+}
+
+func (o *overlay) Int(key string, defValue ...int64) (int64, bool) {
+	return get(o, key, persistence.Section.Int, defValue...)
+}
+
+func (o *overlay) UInt(key string, defValue ...uint64) (uint64, bool) {
+	return get(o, key, persistence.Section.UInt, defValue...)
+}
+
+func (o *overlay) String(key string, defValue ...string) (string, bool) {
+	return get(o, key, persistence.Section.String, defValue...)
+}
+
+func (o *overlay) Bool(key string, defValue ...bool) (bool, bool) {
+	return get(o, key, persistence.Section.Bool, defValue...)
+}
+
+func (o *overlay) Float(key string, defValue ...float64) (float64, bool) {
+	return get(o, key, persistence.Section.Float, defValue...)
+}
+
+func (o *overlay) Bytes(key string, defValue ...[]byte) ([]byte, bool) {
+	return get(o, key, persistence.Section.Bytes, defValue...)
+}
+
+func (o *overlay) Set(key string, value interface{}) {
+	o.writeTop().Set(key, value)
+
+	if t, ok := o.tombstones(); ok {
+		t.Delete(key)
+	}
+}
+
+func (o *overlay) Delete(key string) bool {
+	found := o.Exists(key)
+
+	if t, ok := o.readTop(); ok {
+		t.Delete(key)
+	}
+
+	if o.lowerExists(key) {
+		o.writeTop().Section(TombstoneSection).Set(key, true)
+	}
+
+	return found
+}
+
+func (o *overlay) Sync() {
+	if t, ok := o.readTop(); ok {
+		if s, ok := t.(persistence.Syncer); ok {
+			s.Sync()
+		}
+	}
+}
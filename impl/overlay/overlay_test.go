@@ -0,0 +1,160 @@
+package overlay
+
+import (
+	"github.com/shimmeringbee/persistence"
+	"github.com/shimmeringbee/persistence/impl/memory"
+	"github.com/shimmeringbee/persistence/impl/test"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestOverlay(t *testing.T) {
+	test.Impl{
+		New: func() persistence.Section {
+			return New(memory.New(), memory.New())
+		},
+		Done:   test.EmptyDone,
+		Switch: test.EmptySwitch,
+	}.Test(t)
+}
+
+func TestOverlay_Layers(t *testing.T) {
+	t.Run("reads are resolved from the top most layer", func(t *testing.T) {
+		defaults := memory.New()
+		defaults.Set("a", "default")
+		defaults.Set("b", "default")
+
+		user := memory.New()
+		user.Set("a", "user")
+
+		o := New(user, defaults)
+
+		a, found := o.String("a")
+		assert.True(t, found)
+		assert.Equal(t, "user", a)
+
+		b, found := o.String("b")
+		assert.True(t, found)
+		assert.Equal(t, "default", b)
+
+		assert.Equal(t, []string{"a", "b"}, o.Keys())
+	})
+
+	t.Run("writes only modify the top layer", func(t *testing.T) {
+		defaults := memory.New()
+		defaults.Section("quirks").Set("a", 1)
+
+		user := memory.New()
+
+		o := New(user, defaults)
+		o.Section("quirks").Set("a", 2)
+
+		v, _ := o.Section("quirks").Int("a")
+		assert.Equal(t, int64(2), v)
+
+		v, _ = defaults.Section("quirks").Int("a")
+		assert.Equal(t, int64(1), v)
+
+		v, _ = user.Section("quirks").Int("a")
+		assert.Equal(t, int64(2), v)
+	})
+
+	t.Run("deleting a key present in a lower layer hides it", func(t *testing.T) {
+		defaults := memory.New()
+		defaults.Set("a", "default")
+
+		user := memory.New()
+		user.Set("a", "user")
+
+		o := New(user, defaults)
+
+		assert.True(t, o.Delete("a"))
+		assert.False(t, o.Exists("a"))
+		assert.NotContains(t, o.Keys(), "a")
+		assert.False(t, o.Delete("a"))
+		assert.True(t, defaults.Exists("a"))
+
+		o.Set("a", "again")
+
+		v, _ := o.String("a")
+		assert.Equal(t, "again", v)
+	})
+
+	t.Run("deleting a section present in a lower layer hides it", func(t *testing.T) {
+		defaults := memory.New()
+		defaults.Section("s").Set("a", 1)
+
+		o := New(memory.New(), defaults)
+
+		assert.True(t, o.SectionDelete("s"))
+		assert.False(t, o.SectionExists("s"))
+		assert.NotContains(t, o.SectionKeys(), "s")
+		assert.True(t, defaults.SectionExists("s"))
+
+		o.Section("s").Set("b", 2)
+
+		assert.True(t, o.SectionExists("s"))
+		assert.False(t, o.Section("s").Exists("a"))
+		assert.True(t, o.Section("s").Exists("b"))
+	})
+
+	t.Run("tombstones are persisted in the top layer", func(t *testing.T) {
+		defaults := memory.New()
+		defaults.Set("a", 1)
+
+		user := memory.New()
+
+		assert.True(t, New(user, defaults).Delete("a"))
+		assert.False(t, New(user, defaults).Exists("a"))
+	})
+
+	t.Run("looking up a lower layer section does not create it in the top layer", func(t *testing.T) {
+		defaults := memory.New()
+		defaults.Section("a", "b").Set("key", 1)
+
+		user := memory.New()
+
+		o := New(user, defaults)
+
+		s, found := o.SectionIfExists("a", "b")
+		assert.True(t, found)
+
+		v, _ := s.Int("key")
+		assert.Equal(t, int64(1), v)
+		assert.False(t, user.SectionExists("a"))
+
+		s.Set("key", 2)
+
+		v, _ = user.Section("a", "b").Int("key")
+		assert.Equal(t, int64(2), v)
+	})
+
+	t.Run("a lower layer section can not be moved into its own descendant", func(t *testing.T) {
+		defaults := memory.New()
+		defaults.Section("a", "b").Set("key", 1)
+
+		user := memory.New()
+
+		o := New(user, defaults)
+
+		dst, found := o.SectionIfExists("a", "b")
+		assert.True(t, found)
+
+		done := make(chan bool)
+
+		go func() {
+			done <- o.SectionMove("a", dst, "c")
+		}()
+
+		select {
+		case moved := <-done:
+			assert.False(t, moved)
+		case <-time.After(time.Second):
+			t.Fatal("move into descendant did not return")
+		}
+
+		assert.True(t, o.SectionExists("a"))
+		assert.False(t, o.Section("a", "b").SectionExists("c"))
+	})
+}
@@ -0,0 +1,140 @@
+package readonly
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/shimmeringbee/persistence"
+	"github.com/shimmeringbee/persistence/impl/memory"
+	"iter"
+)
+
+func New(s persistence.Section) persistence.Section {
+	if ro, ok := s.(*readOnly); ok {
+		return ro
+	}
+
+	return &readOnly{s: s}
+}
+
+type readOnly struct {
+	s persistence.Section
+}
+
+var _ persistence.Section = (*readOnly)(nil)
+
+func denied(op string, key string) {
+	panic(fmt.Errorf("section %s %q: %w", op, key, persistence.ErrReadOnly))
+}
+
+func (r *readOnly) Section(key ...string) persistence.Section {
+	if len(key) == 0 {
+		panic(fmt.Errorf("section: %w", persistence.ErrNoSectionKey))
+	}
+
+	if s, ok := r.s.SectionIfExists(key...); ok {
+		return &readOnly{s: s}
+	}
+
+	return &readOnly{s: memory.New()}
+}
+
+func (r *readOnly) SectionIfExists(key ...string) (persistence.Section, bool) {
+	if s, ok := r.s.SectionIfExists(key...); ok {
+		return &readOnly{s: s}, true
+	}
+
+	return nil, false
+}
+
+func (r *readOnly) SectionKeys() []string {
+	return r.s.SectionKeys()
+}
+
+func (r *readOnly) AllSections(prefix ...string) iter.Seq2[string, persistence.Section] {
+	return func(yield func(string, persistence.Section) bool) {
+		for k, s := range r.s.AllSections(prefix...) {
+			if !yield(k, &readOnly{s: s}) {
+				return
+			}
+		}
+	}
+}
+
+func (r *readOnly) SectionExists(key string) bool {
+	return r.s.SectionExists(key)
+}
+
+func (r *readOnly) SectionDelete(key string) bool {
+	denied("delete", key)
+	return false
+}
+
+func (r *readOnly) SectionRename(key string, _ string) bool {
+	denied("rename", key)
+	return false
+}
+
+func (r *readOnly) SectionMove(key string, _ persistence.Section, _ string) bool {
+	denied("move", key)
+	return false
+}
+
+func (r *readOnly) Keys() []string {
+	return r.s.Keys()
+}
+
+func (r *readOnly) All(prefix ...string) iter.Seq2[string, persistence.ValueType] {
+	return r.s.All(prefix...)
+}
+
+func (r *readOnly) Walk(fn func(key string, vt persistence.ValueType, value any) bool, prefix ...string) {
+	r.s.Walk(func(key string, vt persistence.ValueType, value any) bool {
+		if b, ok := value.([]byte); ok {
+			value = bytes.Clone(b)
+		}
+
+		return fn(key, vt, value)
+	}, prefix...)
+}
+
+func (r *readOnly) Exists(key string) bool {
+	return r.s.Exists(key)
+}
+
+func (r *readOnly) Type(key string) persistence.ValueType {
+	return r.s.Type(key)
+}
+
+func (r *readOnly) Int(key string, defValue ...int64) (int64, bool) {
+	return r.s.Int(key, defValue...)
+}
+
+func (r *readOnly) UInt(key string, defValue ...uint64) (uint64, bool) {
+	return r.s.UInt(key, defValue...)
+}
+
+func (r *readOnly) String(key string, defValue ...string) (string, bool) {
+	return r.s.String(key, defValue...)
+}
+
+func (r *readOnly) Bool(key string, defValue ...bool) (bool, bool) {
+	return r.s.Bool(key, defValue...)
+}
+
+func (r *readOnly) Float(key string, defValue ...float64) (float64, bool) {
+	return r.s.Float(key, defValue...)
+}
+
+func (r *readOnly) Bytes(key string, defValue ...[]byte) ([]byte, bool) {
+	v, found := r.s.Bytes(key, defValue...)
+	return bytes.Clone(v), found
+}
+
+func (r *readOnly) Set(key string, _ interface{}) {
+	denied("set", key)
+}
+
+func (r *readOnly) Delete(key string) bool {
+	denied("delete", key)
+	return false
+}
@@ -0,0 +1,88 @@
+package readonly
+
+import (
+	"github.com/shimmeringbee/persistence"
+	"github.com/shimmeringbee/persistence/impl/memory"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestReadOnly(t *testing.T) {
+	t.Run("values and sections can be read", func(t *testing.T) {
+		s := memory.New()
+		s.Set("key", "value")
+		s.Section("a", "b").Set("key", 1)
+
+		ro := New(s)
+
+		v, found := ro.String("key")
+		assert.True(t, found)
+		assert.Equal(t, "value", v)
+
+		i, found := ro.Section("a", "b").Int("key")
+		assert.True(t, found)
+		assert.Equal(t, int64(1), i)
+
+		assert.Equal(t, []string{"key"}, ro.Keys())
+		assert.Equal(t, []string{"a"}, ro.SectionKeys())
+	})
+
+	t.Run("mutations panic with a read only error", func(t *testing.T) {
+		s := memory.New()
+		s.Set("key", "value")
+		s.Section("a")
+
+		ro := New(s)
+
+		for _, tc := range []struct {
+			name     string
+			fn       func()
+			expected string
+		}{
+			{name: "Set", fn: func() { ro.Set("key", "other") }, expected: `section set "key": section is read only`},
+			{name: "Delete", fn: func() { ro.Delete("key") }, expected: `section delete "key": section is read only`},
+			{name: "SectionDelete", fn: func() { ro.SectionDelete("a") }, expected: `section delete "a": section is read only`},
+			{name: "SectionRename", fn: func() { ro.SectionRename("a", "b") }, expected: `section rename "a": section is read only`},
+			{name: "SectionMove", fn: func() { ro.SectionMove("a", ro, "b") }, expected: `section move "a": section is read only`},
+			{name: "ChildSet", fn: func() { ro.Section("a").Set("key", 1) }, expected: `section set "key": section is read only`},
+		} {
+			assert.PanicsWithError(t, tc.expected, tc.fn, tc.name)
+		}
+
+		v, _ := s.String("key")
+		assert.Equal(t, "value", v)
+		assert.True(t, s.SectionExists("a"))
+	})
+
+	t.Run("panic value matches ErrReadOnly", func(t *testing.T) {
+		ro := New(memory.New())
+
+		defer func() {
+			err, _ := recover().(error)
+			assert.ErrorIs(t, err, persistence.ErrReadOnly)
+		}()
+
+		ro.Set("key", 1)
+	})
+
+	t.Run("accessing missing sections does not create them", func(t *testing.T) {
+		s := memory.New()
+
+		ro := New(s)
+		child := ro.Section("missing", "deeper")
+
+		assert.False(t, child.Exists("key"))
+		assert.False(t, s.SectionExists("missing"))
+	})
+
+	t.Run("returned bytes can not modify the underlying section", func(t *testing.T) {
+		s := memory.New()
+		s.Set("key", []byte{0x01})
+
+		b, _ := New(s).Bytes("key")
+		b[0] = 0xff
+
+		b, _ = s.Bytes("key")
+		assert.Equal(t, []byte{0x01}, b)
+	})
+}
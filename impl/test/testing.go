@@ -3,6 +3,7 @@ package test
 import (
 	"github.com/shimmeringbee/persistence"
 	"github.com/stretchr/testify/assert"
+	"math"
 	"testing"
 )
 
@@ -35,6 +36,14 @@ func (tt Impl) Test(t *testing.T) {
 		"SectionDelete":      tt.SectionDelete,
 		"Exists":             tt.Exists,
 		"SectionKeyNotClash": tt.SectionKeyNotClash,
+		"All":                tt.All,
+		"AllSections":        tt.AllSections,
+		"Walk":               tt.Walk,
+		"SectionRename":      tt.SectionRename,
+		"SectionMove":        tt.SectionMove,
+		"SectionIfExists":    tt.SectionIfExists,
+		"SectionNoKey":       tt.SectionNoKey,
+		"Coerce":             tt.Coerce,
 	} {
 		t.Run(name, func(t *testing.T) {
 			test(t)
@@ -408,3 +417,498 @@ func (tt Impl) SectionKeyNotClash(t *testing.T) {
 		assert.NotContains(t, s2.SectionKeys(), "key3")
 	})
 }
+
+func (tt Impl) All(t *testing.T) {
+	t.Run("keys and types are returned in sorted order", func(t *testing.T) {
+		s := tt.New()
+		defer tt.Done(s)
+
+		s.Set("c", "three")
+		s.Set("a", 1)
+		s.Set("b", true)
+
+		s2 := tt.Switch(s)
+		defer tt.Done(s2)
+
+		var keys []string
+		var types []persistence.ValueType
+
+		for k, vt := range s2.All() {
+			keys = append(keys, k)
+			types = append(types, vt)
+		}
+
+		assert.Equal(t, []string{"a", "b", "c"}, keys)
+		assert.Equal(t, []persistence.ValueType{persistence.Int, persistence.Bool, persistence.String}, types)
+	})
+
+	t.Run("keys are filtered by prefix", func(t *testing.T) {
+		s := tt.New()
+		defer tt.Done(s)
+
+		s.Set("attr:2", 2)
+		s.Set("attr:1", 1)
+		s.Set("other", 3)
+
+		var keys []string
+
+		for k := range s.All("attr:") {
+			keys = append(keys, k)
+		}
+
+		assert.Equal(t, []string{"attr:1", "attr:2"}, keys)
+	})
+
+	t.Run("iteration stops when requested", func(t *testing.T) {
+		s := tt.New()
+		defer tt.Done(s)
+
+		s.Set("a", 1)
+		s.Set("b", 2)
+
+		var keys []string
+
+		for k := range s.All() {
+			keys = append(keys, k)
+			break
+		}
+
+		assert.Equal(t, []string{"a"}, keys)
+	})
+}
+
+func (tt Impl) AllSections(t *testing.T) {
+	t.Run("sections are returned in sorted order", func(t *testing.T) {
+		s := tt.New()
+		defer tt.Done(s)
+
+		s.Section("two").Set("key", "2")
+		s.Section("one").Set("key", "1")
+		s.Section("three").Set("key", "3")
+
+		s2 := tt.Switch(s)
+		defer tt.Done(s2)
+
+		var keys []string
+		var values []string
+
+		for k, ss := range s2.AllSections() {
+			keys = append(keys, k)
+			v, _ := ss.String("key")
+			values = append(values, v)
+		}
+
+		assert.Equal(t, []string{"one", "three", "two"}, keys)
+		assert.Equal(t, []string{"1", "3", "2"}, values)
+	})
+
+	t.Run("sections are filtered by prefix", func(t *testing.T) {
+		s := tt.New()
+		defer tt.Done(s)
+
+		s.Section("device-b")
+		s.Section("device-a")
+		s.Section("zone-a")
+
+		var keys []string
+
+		for k := range s.AllSections("device-") {
+			keys = append(keys, k)
+		}
+
+		assert.Equal(t, []string{"device-a", "device-b"}, keys)
+	})
+}
+
+func (tt Impl) Walk(t *testing.T) {
+	t.Run("keys, types and values are walked in sorted order", func(t *testing.T) {
+		s := tt.New()
+		defer tt.Done(s)
+
+		s.Set("b", "two")
+		s.Set("a", uint(1))
+		s.Set("c", []byte{0x03})
+
+		s2 := tt.Switch(s)
+		defer tt.Done(s2)
+
+		var keys []string
+		var types []persistence.ValueType
+		var values []any
+
+		s2.Walk(func(k string, vt persistence.ValueType, v any) bool {
+			keys = append(keys, k)
+			types = append(types, vt)
+			values = append(values, v)
+			return true
+		})
+
+		assert.Equal(t, []string{"a", "b", "c"}, keys)
+		assert.Equal(t, []persistence.ValueType{persistence.UnsignedInt, persistence.String, persistence.Bytes}, types)
+		assert.Equal(t, []any{uint64(1), "two", []byte{0x03}}, values)
+	})
+
+	t.Run("walk stops when the function returns false", func(t *testing.T) {
+		s := tt.New()
+		defer tt.Done(s)
+
+		s.Set("a", 1)
+		s.Set("b", 2)
+		s.Set("c", 3)
+
+		count := 0
+
+		s.Walk(func(_ string, _ persistence.ValueType, _ any) bool {
+			count++
+			return count < 2
+		})
+
+		assert.Equal(t, 2, count)
+	})
+
+	t.Run("walk is filtered by prefix", func(t *testing.T) {
+		s := tt.New()
+		defer tt.Done(s)
+
+		s.Set("x1", 1)
+		s.Set("y1", 2)
+		s.Set("x2", 3)
+
+		var keys []string
+
+		s.Walk(func(k string, _ persistence.ValueType, _ any) bool {
+			keys = append(keys, k)
+			return true
+		}, "x")
+
+		assert.Equal(t, []string{"x1", "x2"}, keys)
+	})
+}
+
+func (tt Impl) SectionRename(t *testing.T) {
+	t.Run("renamed section retains keys and subsections", func(t *testing.T) {
+		s := tt.New()
+		defer tt.Done(s)
+
+		s.Section("old").Set("key", "value")
+		s.Section("old", "child").Set("key", 42)
+
+		assert.True(t, s.SectionRename("old", "new"))
+
+		s2 := tt.Switch(s)
+		defer tt.Done(s2)
+
+		assert.False(t, s2.SectionExists("old"))
+		assert.True(t, s2.SectionExists("new"))
+
+		v, _ := s2.Section("new").String("key")
+		assert.Equal(t, "value", v)
+
+		i, _ := s2.Section("new", "child").Int("key")
+		assert.Equal(t, int64(42), i)
+	})
+
+	t.Run("sections modified after rename are persisted at new location", func(t *testing.T) {
+		s := tt.New()
+		defer tt.Done(s)
+
+		old := s.Section("old", "child")
+
+		assert.True(t, s.SectionRename("old", "new"))
+		old.Set("key", "value")
+
+		s2 := tt.Switch(s)
+		defer tt.Done(s2)
+
+		v, _ := s2.Section("new", "child").String("key")
+		assert.Equal(t, "value", v)
+	})
+
+	t.Run("fails if source is missing or destination exists", func(t *testing.T) {
+		s := tt.New()
+		defer tt.Done(s)
+
+		s.Section("one")
+		s.Section("two")
+
+		assert.False(t, s.SectionRename("missing", "three"))
+		assert.False(t, s.SectionRename("one", "two"))
+		assert.True(t, s.SectionExists("one"))
+	})
+}
+
+func (tt Impl) SectionMove(t *testing.T) {
+	t.Run("section is moved under a new parent", func(t *testing.T) {
+		s := tt.New()
+		defer tt.Done(s)
+
+		s.Section("devices", "a").Set("key", "value")
+		s.Section("archive")
+
+		assert.True(t, s.Section("devices").SectionMove("a", s.Section("archive"), "b"))
+
+		s2 := tt.Switch(s)
+		defer tt.Done(s2)
+
+		assert.False(t, s2.Section("devices").SectionExists("a"))
+		assert.True(t, s2.Section("archive").SectionExists("b"))
+
+		v, _ := s2.Section("archive", "b").String("key")
+		assert.Equal(t, "value", v)
+	})
+
+	t.Run("section can not be moved into itself", func(t *testing.T) {
+		s := tt.New()
+		defer tt.Done(s)
+
+		s.Section("a", "b", "c")
+
+		assert.False(t, s.SectionMove("a", s.Section("a", "b"), "d"))
+		assert.False(t, s.SectionMove("a", s.Section("a"), "d"))
+		assert.True(t, s.SectionExists("a"))
+	})
+
+	t.Run("section can be moved to a parent", func(t *testing.T) {
+		s := tt.New()
+		defer tt.Done(s)
+
+		s.Section("a", "b").Set("key", "value")
+
+		assert.True(t, s.Section("a").SectionMove("b", s, "b"))
+
+		s2 := tt.Switch(s)
+		defer tt.Done(s2)
+
+		assert.False(t, s2.Section("a").SectionExists("b"))
+
+		v, _ := s2.Section("b").String("key")
+		assert.Equal(t, "value", v)
+	})
+}
+
+func (tt Impl) SectionIfExists(t *testing.T) {
+	t.Run("returns existing chained sections", func(t *testing.T) {
+		s := tt.New()
+		defer tt.Done(s)
+
+		s.Section("tier1", "tier2").Set("key", "value")
+
+		s2 := tt.Switch(s)
+		defer tt.Done(s2)
+
+		cs, found := s2.SectionIfExists("tier1", "tier2")
+		assert.True(t, found)
+
+		v, _ := cs.String("key")
+		assert.Equal(t, "value", v)
+	})
+
+	t.Run("does not create missing sections", func(t *testing.T) {
+		s := tt.New()
+		defer tt.Done(s)
+
+		s.Section("tier1")
+
+		cs, found := s.SectionIfExists("tier1", "missing", "deeper")
+		assert.False(t, found)
+		assert.Nil(t, cs)
+
+		_, found = s.SectionIfExists("missing")
+		assert.False(t, found)
+
+		s2 := tt.Switch(s)
+		defer tt.Done(s2)
+
+		assert.Equal(t, []string{"tier1"}, s2.SectionKeys())
+		assert.Empty(t, s2.Section("tier1").SectionKeys())
+	})
+
+	t.Run("returns false when no keys are provided", func(t *testing.T) {
+		s := tt.New()
+		defer tt.Done(s)
+
+		_, found := s.SectionIfExists()
+		assert.False(t, found)
+	})
+}
+
+func (tt Impl) SectionNoKey(t *testing.T) {
+	t.Run("section without a key panics with ErrNoSectionKey", func(t *testing.T) {
+		s := tt.New()
+		defer tt.Done(s)
+
+		defer func() {
+			err, _ := recover().(error)
+			assert.ErrorIs(t, err, persistence.ErrNoSectionKey)
+		}()
+
+		s.Section()
+	})
+}
+
+func setMixedNumbers(s persistence.Section) {
+	s.Set("uint", uint(5))
+	s.Set("int", -5)
+	s.Set("float", 2.5)
+	s.Set("whole", 3.0)
+	s.Set("string", "42")
+	s.Set("text", "abc")
+	s.Set("big", uint64(math.MaxUint64))
+	s.Set("precise", int64(1<<53+1))
+}
+
+func (tt Impl) Coerce(t *testing.T) {
+	t.Run("numeric values are converted when lossless", func(t *testing.T) {
+		s := tt.New()
+		defer tt.Done(s)
+
+		setMixedNumbers(s)
+
+		s2 := tt.Switch(s)
+		defer tt.Done(s2)
+
+		i, found, err := persistence.CoerceInt(s2, "uint")
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, int64(5), i)
+
+		f, found, err := persistence.CoerceFloat(s2, "int")
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, -5.0, f)
+
+		u, found, err := persistence.CoerceUInt(s2, "whole")
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, uint64(3), u)
+
+		i, found, err = persistence.CoerceInt(s2, "string")
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, int64(42), i)
+	})
+
+	t.Run("lossy conversions are reported and return the default", func(t *testing.T) {
+		s := tt.New()
+		defer tt.Done(s)
+
+		setMixedNumbers(s)
+
+		s2 := tt.Switch(s)
+		defer tt.Done(s2)
+
+		u, found, err := persistence.CoerceUInt(s2, "int", 7)
+		assert.ErrorIs(t, err, persistence.ErrLossy)
+		assert.False(t, found)
+		assert.Equal(t, uint64(7), u)
+
+		_, _, err = persistence.CoerceInt(s2, "float")
+		assert.ErrorIs(t, err, persistence.ErrLossy)
+
+		_, _, err = persistence.CoerceInt(s2, "big")
+		assert.ErrorIs(t, err, persistence.ErrLossy)
+
+		_, _, err = persistence.CoerceFloat(s2, "precise")
+		assert.ErrorIs(t, err, persistence.ErrLossy)
+	})
+
+	t.Run("non numeric and missing values are not found", func(t *testing.T) {
+		s := tt.New()
+		defer tt.Done(s)
+
+		setMixedNumbers(s)
+		s.Set("bool", true)
+
+		_, found, err := persistence.CoerceFloat(s, "text")
+		assert.ErrorIs(t, err, persistence.ErrNotNumeric)
+		assert.False(t, found)
+
+		_, found, err = persistence.CoerceInt(s, "bool")
+		assert.ErrorIs(t, err, persistence.ErrNotNumeric)
+		assert.False(t, found)
+
+		i, found, err := persistence.CoerceInt(s, "missing", 1)
+		assert.NoError(t, err)
+		assert.False(t, found)
+		assert.Equal(t, int64(1), i)
+	})
+}
+
+func (tt Impl) TestCoercing(t *testing.T) {
+	t.Run("getters convert numeric values when lossless", func(t *testing.T) {
+		s := tt.New()
+		defer tt.Done(s)
+
+		setMixedNumbers(s)
+
+		s2 := tt.Switch(s)
+		defer tt.Done(s2)
+
+		i, found := s2.Int("uint")
+		assert.True(t, found)
+		assert.Equal(t, int64(5), i)
+
+		f, found := s2.Float("int")
+		assert.True(t, found)
+		assert.Equal(t, -5.0, f)
+
+		u, found := s2.UInt("whole")
+		assert.True(t, found)
+		assert.Equal(t, uint64(3), u)
+
+		i, found = s2.Int("string")
+		assert.True(t, found)
+		assert.Equal(t, int64(42), i)
+
+		u, found = s2.UInt("big")
+		assert.True(t, found)
+		assert.Equal(t, uint64(math.MaxUint64), u)
+
+		str, found := s2.String("string")
+		assert.True(t, found)
+		assert.Equal(t, "42", str)
+
+		assert.Equal(t, persistence.UnsignedInt, s2.Type("uint"))
+	})
+
+	t.Run("getters return the default for lossy or non numeric values", func(t *testing.T) {
+		s := tt.New()
+		defer tt.Done(s)
+
+		setMixedNumbers(s)
+
+		s2 := tt.Switch(s)
+		defer tt.Done(s2)
+
+		u, found := s2.UInt("int", 7)
+		assert.False(t, found)
+		assert.Equal(t, uint64(7), u)
+
+		i, found := s2.Int("float", 7)
+		assert.False(t, found)
+		assert.Equal(t, int64(7), i)
+
+		_, found = s2.Float("precise")
+		assert.False(t, found)
+
+		_, found = s2.Int("text")
+		assert.False(t, found)
+
+		_, found = s2.String("int")
+		assert.False(t, found)
+	})
+
+	t.Run("sections inherit coercion", func(t *testing.T) {
+		s := tt.New()
+		defer tt.Done(s)
+
+		s.Section("a", "b").Set("key", uint8(1))
+
+		s2 := tt.Switch(s)
+		defer tt.Done(s2)
+
+		i, found := s2.Section("a", "b").Int("key")
+		assert.True(t, found)
+		assert.Equal(t, int64(1), i)
+	})
+}
@@ -1,12 +1,26 @@
 package persistence
 
+import (
+	"errors"
+	"iter"
+)
+
+var ErrReadOnly = errors.New("section is read only")
+var ErrNoSectionKey = errors.New("no section key provided")
+
 type Section interface {
 	Section(key ...string) Section
+	SectionIfExists(key ...string) (Section, bool)
 	SectionKeys() []string
+	AllSections(prefix ...string) iter.Seq2[string, Section]
 	SectionExists(key string) bool
 	SectionDelete(key string) bool
+	SectionRename(key string, newKey string) bool
+	SectionMove(key string, dstParent Section, dstKey string) bool
 
 	Keys() []string
+	All(prefix ...string) iter.Seq2[string, ValueType]
+	Walk(fn func(key string, vt ValueType, value any) bool, prefix ...string)
 	Exists(key string) bool
 	Type(key string) ValueType
 
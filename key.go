@@ -0,0 +1,38 @@
+package persistence
+
+type Key[T any] struct {
+	Name    string
+	Default T
+	Encode  func(Section, string, T)
+	Decode  func(Section, string) (T, bool)
+}
+
+func NewKey[T any](name string, defValue T, enc func(Section, string, T), dec func(Section, string) (T, bool)) Key[T] {
+	return Key[T]{Name: name, Default: defValue, Encode: enc, Decode: dec}
+}
+
+func (k Key[T]) Get(s Section) (T, bool) {
+	if v, found := k.Decode(s, k.Name); found {
+		return v, true
+	} else {
+		return k.Default, false
+	}
+}
+
+func (k Key[T]) Set(s Section, v T) {
+	k.Encode(s, k.Name, v)
+}
+
+func (k Key[T]) Delete(s Section) bool {
+	deleted := s.Delete(k.Name)
+	return s.SectionDelete(k.Name) || deleted
+}
+
+func (k Key[T]) Exists(s Section) bool {
+	if s.Exists(k.Name) {
+		return true
+	}
+
+	_, found := s.SectionIfExists(k.Name)
+	return found
+}
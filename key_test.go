@@ -0,0 +1,72 @@
+package persistence_test
+
+import (
+	"github.com/shimmeringbee/persistence"
+	"github.com/shimmeringbee/persistence/converter"
+	"github.com/shimmeringbee/persistence/impl/memory"
+	"github.com/shimmeringbee/zigbee"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var networkAddress = persistence.Key[zigbee.NetworkAddress]{
+	Name:    "networkAddress",
+	Default: zigbee.BroadcastAll,
+	Encode:  converter.NetworkAddressEncoder,
+	Decode:  converter.NetworkAddressDecoder,
+}
+
+var nodeDescription = persistence.NewKey("nodeDescription", zigbee.NodeDescription{}, converter.NodeDescriptionEncoder, converter.NodeDescriptionDecoder)
+
+func TestKey(t *testing.T) {
+	t.Run("stored and retrieved", func(t *testing.T) {
+		s := memory.New()
+
+		networkAddress.Set(s, 0x1122)
+
+		actual, found := networkAddress.Get(s)
+		assert.True(t, found)
+		assert.Equal(t, zigbee.NetworkAddress(0x1122), actual)
+
+		stored, _ := s.Int("networkAddress")
+		assert.Equal(t, int64(0x1122), stored)
+	})
+
+	t.Run("default is returned when not found", func(t *testing.T) {
+		s := memory.New()
+
+		actual, found := networkAddress.Get(s)
+		assert.False(t, found)
+		assert.Equal(t, zigbee.BroadcastAll, actual)
+		assert.False(t, networkAddress.Exists(s))
+	})
+
+	t.Run("deleting removes the value", func(t *testing.T) {
+		s := memory.New()
+
+		networkAddress.Set(s, 0x1122)
+		assert.True(t, networkAddress.Exists(s))
+
+		assert.True(t, networkAddress.Delete(s))
+		assert.False(t, networkAddress.Delete(s))
+
+		_, found := networkAddress.Get(s)
+		assert.False(t, found)
+	})
+
+	t.Run("section based converters are stored, retrieved and deleted", func(t *testing.T) {
+		s := memory.New()
+
+		expected := zigbee.NodeDescription{LogicalType: zigbee.Router, ManufacturerCode: 0x1234}
+
+		nodeDescription.Set(s, expected)
+		assert.True(t, nodeDescription.Exists(s))
+
+		actual, found := nodeDescription.Get(s)
+		assert.True(t, found)
+		assert.Equal(t, expected, actual)
+
+		assert.True(t, nodeDescription.Delete(s))
+		assert.False(t, nodeDescription.Exists(s))
+	})
+}
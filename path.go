@@ -0,0 +1,138 @@
+package persistence
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const PathSeparator = "/"
+
+var ErrInvalidPath = errors.New("invalid path")
+
+func SplitPath(path string) ([]string, string, error) {
+	parts := strings.Split(path, PathSeparator)
+
+	for _, p := range parts {
+		if len(p) == 0 {
+			return nil, "", fmt.Errorf("%w: %q", ErrInvalidPath, path)
+		}
+	}
+
+	return parts[:len(parts)-1], parts[len(parts)-1], nil
+}
+
+func JoinPath(elem ...string) string {
+	return strings.Join(elem, PathSeparator)
+}
+
+// OpenSection returns an error wrapping ErrNoSectionKey when no key is given. Section itself still panics with
+// ErrNoSectionKey in that case, so use OpenSection where the keys are not known to be non-empty.
+func OpenSection(s Section, key ...string) (Section, error) {
+	if len(key) == 0 {
+		return nil, fmt.Errorf("section: %w", ErrNoSectionKey)
+	}
+
+	return s.Section(key...), nil
+}
+
+func lookupSection(s Section, sections []string) (Section, bool) {
+	if len(sections) == 0 {
+		return s, true
+	}
+
+	return s.SectionIfExists(sections...)
+}
+
+func Get(s Section, key string) (any, ValueType, bool) {
+	vt := s.Type(key)
+
+	var v any
+	var found bool
+
+	switch vt {
+	case Int:
+		v, found = s.Int(key)
+	case UnsignedInt:
+		v, found = s.UInt(key)
+	case String:
+		v, found = s.String(key)
+	case Bool:
+		v, found = s.Bool(key)
+	case Float:
+		v, found = s.Float(key)
+	case Bytes:
+		v, found = s.Bytes(key)
+	}
+
+	if !found {
+		return nil, None, false
+	}
+
+	return v, vt, true
+}
+
+func GetPath(s Section, path string) (any, ValueType, bool) {
+	sections, key, err := SplitPath(path)
+	if err != nil {
+		return nil, None, false
+	}
+
+	if s, found := lookupSection(s, sections); found {
+		return Get(s, key)
+	}
+
+	return nil, None, false
+}
+
+func SetPath(s Section, path string, value any) error {
+	sections, key, err := SplitPath(path)
+	if err != nil {
+		return err
+	}
+
+	if len(sections) > 0 {
+		s = s.Section(sections...)
+	}
+
+	s.Set(key, value)
+	return nil
+}
+
+func DeletePath(s Section, path string) bool {
+	sections, key, err := SplitPath(path)
+	if err != nil {
+		return false
+	}
+
+	if s, found := lookupSection(s, sections); found {
+		return s.Delete(key)
+	}
+
+	return false
+}
+
+func Walk(s Section, fn func(path string, vt ValueType, value any) bool) {
+	walk(s, "", fn)
+}
+
+func walk(s Section, base string, fn func(path string, vt ValueType, value any) bool) bool {
+	cont := true
+
+	s.Walk(func(k string, vt ValueType, v any) bool {
+		cont = fn(base+k, vt, v)
+		return cont
+	})
+
+	if !cont {
+		return false
+	}
+
+	for k, ss := range s.AllSections() {
+		if !walk(ss, base+k+PathSeparator, fn) {
+			return false
+		}
+	}
+
+	return true
+}
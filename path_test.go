@@ -0,0 +1,169 @@
+package persistence_test
+
+import (
+	"github.com/shimmeringbee/persistence"
+	"github.com/shimmeringbee/persistence/impl/file"
+	"github.com/shimmeringbee/persistence/impl/memory"
+	"github.com/shimmeringbee/persistence/impl/overlay"
+	"github.com/shimmeringbee/persistence/impl/readonly"
+	"github.com/shimmeringbee/persistence/schema"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSplitPath(t *testing.T) {
+	t.Run("path is split into sections and key", func(t *testing.T) {
+		sections, key, err := persistence.SplitPath("devices/0x00124b/endpoints/1/clusters:0x0006")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"devices", "0x00124b", "endpoints", "1"}, sections)
+		assert.Equal(t, "clusters:0x0006", key)
+	})
+
+	t.Run("path with only a key has no sections", func(t *testing.T) {
+		sections, key, err := persistence.SplitPath("key")
+		assert.NoError(t, err)
+		assert.Empty(t, sections)
+		assert.Equal(t, "key", key)
+	})
+
+	t.Run("empty elements are rejected", func(t *testing.T) {
+		for _, p := range []string{"", "/key", "a//key", "a/"} {
+			_, _, err := persistence.SplitPath(p)
+			assert.ErrorIs(t, err, persistence.ErrInvalidPath, p)
+		}
+	})
+}
+
+func TestGetSetDeletePath(t *testing.T) {
+	t.Run("values set by path can be retrieved by path and directly", func(t *testing.T) {
+		s := memory.New()
+
+		assert.NoError(t, persistence.SetPath(s, "devices/0x00124b/endpoints/1/clusters:0x0006", uint64(6)))
+
+		v, vt, found := persistence.GetPath(s, "devices/0x00124b/endpoints/1/clusters:0x0006")
+		assert.True(t, found)
+		assert.Equal(t, persistence.UnsignedInt, vt)
+		assert.Equal(t, uint64(6), v)
+
+		actual, found := s.Section("devices", "0x00124b", "endpoints", "1").UInt("clusters:0x0006")
+		assert.True(t, found)
+		assert.Equal(t, uint64(6), actual)
+	})
+
+	t.Run("getting a missing path does not create sections", func(t *testing.T) {
+		s := memory.New()
+
+		_, vt, found := persistence.GetPath(s, "devices/missing/key")
+		assert.False(t, found)
+		assert.Equal(t, persistence.None, vt)
+		assert.False(t, s.SectionExists("devices"))
+	})
+
+	t.Run("setting an invalid path returns an error", func(t *testing.T) {
+		s := memory.New()
+
+		assert.ErrorIs(t, persistence.SetPath(s, "devices//key", 1), persistence.ErrInvalidPath)
+	})
+
+	t.Run("deleting by path removes the value", func(t *testing.T) {
+		s := memory.New()
+		s.Section("a", "b").Set("key", "value")
+
+		assert.True(t, persistence.DeletePath(s, "a/b/key"))
+		assert.False(t, s.Section("a", "b").Exists("key"))
+		assert.False(t, persistence.DeletePath(s, "a/b/key"))
+		assert.False(t, persistence.DeletePath(s, "missing/key"))
+	})
+}
+
+func TestWalk(t *testing.T) {
+	t.Run("values are walked depth first with full paths", func(t *testing.T) {
+		s := memory.New()
+		s.Set("root", "r")
+		s.Section("b").Set("key", int64(2))
+		s.Section("a", "inner").Set("key", true)
+		s.Section("a").Set("key", 1.5)
+
+		var paths []string
+		var values []any
+
+		persistence.Walk(s, func(path string, _ persistence.ValueType, v any) bool {
+			paths = append(paths, path)
+			values = append(values, v)
+			return true
+		})
+
+		assert.Equal(t, []string{"root", "a/key", "a/inner/key", "b/key"}, paths)
+		assert.Equal(t, []any{"r", 1.5, true, int64(2)}, values)
+	})
+
+	t.Run("walk stops when the function returns false", func(t *testing.T) {
+		s := memory.New()
+		s.Section("a").Set("key", 1)
+		s.Section("b").Set("key", 2)
+
+		var paths []string
+
+		persistence.Walk(s, func(path string, _ persistence.ValueType, _ any) bool {
+			paths = append(paths, path)
+			return false
+		})
+
+		assert.Equal(t, []string{"a/key"}, paths)
+	})
+}
+
+func TestGet(t *testing.T) {
+	t.Run("returns value and type of key", func(t *testing.T) {
+		s := memory.New()
+		s.Set("key", 1.5)
+
+		v, vt, found := persistence.Get(s, "key")
+		assert.True(t, found)
+		assert.Equal(t, persistence.Float, vt)
+		assert.Equal(t, 1.5, v)
+
+		_, vt, found = persistence.Get(s, "missing")
+		assert.False(t, found)
+		assert.Equal(t, persistence.None, vt)
+	})
+}
+
+func TestOpenSection(t *testing.T) {
+	t.Run("chained sections are created and returned", func(t *testing.T) {
+		s := memory.New()
+
+		ss, err := persistence.OpenSection(s, "a", "b")
+		assert.NoError(t, err)
+
+		ss.Set("key", 1)
+
+		v, _ := s.Section("a", "b").Int("key")
+		assert.Equal(t, int64(1), v)
+	})
+
+	t.Run("no keys returns ErrNoSectionKey instead of panicking", func(t *testing.T) {
+		s := memory.New()
+
+		ss, err := persistence.OpenSection(s)
+		assert.ErrorIs(t, err, persistence.ErrNoSectionKey)
+		assert.Nil(t, ss)
+	})
+
+	t.Run("section with no keys still panics in every implementation", func(t *testing.T) {
+		for name, s := range map[string]persistence.Section{
+			"memory":   memory.New(),
+			"file":     file.New(t.TempDir()),
+			"overlay":  overlay.New(memory.New(), memory.New()),
+			"readonly": readonly.New(memory.New()),
+			"schema":   schema.Enforce(memory.New(), schema.Schema{}),
+		} {
+			t.Run(name, func(t *testing.T) {
+				assert.PanicsWithError(t, "section: "+persistence.ErrNoSectionKey.Error(), func() { s.Section() })
+
+				_, err := persistence.OpenSection(s)
+				assert.ErrorIs(t, err, persistence.ErrNoSectionKey)
+			})
+		}
+	})
+}
@@ -0,0 +1,255 @@
+package schema
+
+import (
+	"fmt"
+	"github.com/shimmeringbee/persistence"
+	"iter"
+)
+
+func Enforce(s persistence.Section, sc Schema) persistence.Section {
+	return &enforced{s: s, sc: sc}
+}
+
+type enforced struct {
+	s    persistence.Section
+	sc   Schema
+	path string
+}
+
+var _ persistence.Section = (*enforced)(nil)
+
+var closed = Schema{Closed: true}
+
+func normalise(value any) (any, persistence.ValueType) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), persistence.Int
+	case int8:
+		return int64(v), persistence.Int
+	case int16:
+		return int64(v), persistence.Int
+	case int32:
+		return int64(v), persistence.Int
+	case int64:
+		return v, persistence.Int
+	case uint:
+		return uint64(v), persistence.UnsignedInt
+	case uint8:
+		return uint64(v), persistence.UnsignedInt
+	case uint16:
+		return uint64(v), persistence.UnsignedInt
+	case uint32:
+		return uint64(v), persistence.UnsignedInt
+	case uint64:
+		return v, persistence.UnsignedInt
+	case float32:
+		return float64(v), persistence.Float
+	case float64:
+		return v, persistence.Float
+	case string:
+		return v, persistence.String
+	case bool:
+		return v, persistence.Bool
+	case []byte:
+		return v, persistence.Bytes
+	}
+
+	return value, persistence.None
+}
+
+func violate(kind Kind, path string, err error) {
+	panic(Violation{Path: path, Kind: kind, Err: err})
+}
+
+func (e *enforced) child(key string, s persistence.Section) *enforced {
+	sc, ok := e.sc.section(key)
+	if !ok {
+		sc = closed
+	}
+
+	return &enforced{s: s, sc: sc, path: e.path + key + persistence.PathSeparator}
+}
+
+func (e *enforced) admit(key string, src persistence.Section) {
+	sc, ok := e.sc.section(key)
+	if !ok {
+		violate(Unexpected, e.path+key, nil)
+	}
+
+	if src == nil {
+		return
+	}
+
+	var violations []Violation
+	sc.validate(&violations, e.path+key+persistence.PathSeparator, src)
+
+	if len(violations) > 0 {
+		panic(violations[0])
+	}
+}
+
+func (e *enforced) release(key string) {
+	if sc, ok := e.sc.Sections[key]; ok && sc.Required {
+		violate(MissingSection, e.path+key, nil)
+	}
+}
+
+func (e *enforced) Section(key ...string) persistence.Section {
+	if len(key) == 0 {
+		panic(fmt.Errorf("section: %w", persistence.ErrNoSectionKey))
+	}
+
+	c := e
+
+	for _, k := range key {
+		if !c.s.SectionExists(k) {
+			c.admit(k, nil)
+		}
+
+		c = c.child(k, c.s.Section(k))
+	}
+
+	return c
+}
+
+func (e *enforced) SectionIfExists(key ...string) (persistence.Section, bool) {
+	if len(key) == 0 {
+		return nil, false
+	}
+
+	c := e
+
+	for _, k := range key {
+		s, ok := c.s.SectionIfExists(k)
+		if !ok {
+			return nil, false
+		}
+
+		c = c.child(k, s)
+	}
+
+	return c, true
+}
+
+func (e *enforced) SectionKeys() []string {
+	return e.s.SectionKeys()
+}
+
+func (e *enforced) AllSections(prefix ...string) iter.Seq2[string, persistence.Section] {
+	return func(yield func(string, persistence.Section) bool) {
+		for k, s := range e.s.AllSections(prefix...) {
+			if !yield(k, e.child(k, s)) {
+				return
+			}
+		}
+	}
+}
+
+func (e *enforced) SectionExists(key string) bool {
+	return e.s.SectionExists(key)
+}
+
+func (e *enforced) SectionDelete(key string) bool {
+	if e.s.SectionExists(key) {
+		e.release(key)
+	}
+
+	return e.s.SectionDelete(key)
+}
+
+func (e *enforced) SectionRename(key string, newKey string) bool {
+	src, ok := e.s.SectionIfExists(key)
+	if !ok || key == newKey {
+		return e.s.SectionRename(key, newKey)
+	}
+
+	e.release(key)
+	e.admit(newKey, src)
+
+	return e.s.SectionRename(key, newKey)
+}
+
+func (e *enforced) SectionMove(key string, dstParent persistence.Section, dstKey string) bool {
+	src, ok := e.s.SectionIfExists(key)
+	if !ok {
+		return e.s.SectionMove(key, dstParent, dstKey)
+	}
+
+	e.release(key)
+
+	if dst, ok := dstParent.(*enforced); ok {
+		dst.admit(dstKey, src)
+		dstParent = dst.s
+	}
+
+	return e.s.SectionMove(key, dstParent, dstKey)
+}
+
+func (e *enforced) Keys() []string {
+	return e.s.Keys()
+}
+
+func (e *enforced) All(prefix ...string) iter.Seq2[string, persistence.ValueType] {
+	return e.s.All(prefix...)
+}
+
+func (e *enforced) Walk(fn func(key string, vt persistence.ValueType, value any) bool, prefix ...string) {
+	e.s.Walk(fn, prefix...)
+}
+
+func (e *enforced) Exists(key string) bool {
+	return e.s.Exists(key)
+}
+
+func (e *enforced) Type(key string) persistence.ValueType {
+	return e.s.Type(key)
+}
+
+func (e *enforced) Int(key string, defValue ...int64) (int64, bool) {
+	return e.s.Int(key, defValue...)
+}
+
+func (e *enforced) UInt(key string, defValue ...uint64) (uint64, bool) {
+	return e.s.UInt(key, defValue...)
+}
+
+func (e *enforced) String(key string, defValue ...string) (string, bool) {
+	return e.s.String(key, defValue...)
+}
+
+func (e *enforced) Bool(key string, defValue ...bool) (bool, bool) {
+	return e.s.Bool(key, defValue...)
+}
+
+func (e *enforced) Float(key string, defValue ...float64) (float64, bool) {
+	return e.s.Float(key, defValue...)
+}
+
+func (e *enforced) Bytes(key string, defValue ...[]byte) ([]byte, bool) {
+	return e.s.Bytes(key, defValue...)
+}
+
+func (e *enforced) Set(key string, value interface{}) {
+	f, declared := e.sc.Keys[key]
+
+	switch {
+	case declared:
+		v, vt := normalise(value)
+
+		if kind, err := f.check(v, vt); err != nil {
+			violate(kind, e.path+key, err)
+		}
+	case e.sc.Closed:
+		violate(Unexpected, e.path+key, nil)
+	}
+
+	e.s.Set(key, value)
+}
+
+func (e *enforced) Delete(key string) bool {
+	if f, declared := e.sc.Keys[key]; declared && f.Required && e.s.Exists(key) {
+		violate(Missing, e.path+key, nil)
+	}
+
+	return e.s.Delete(key)
+}
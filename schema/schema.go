@@ -0,0 +1,221 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"github.com/shimmeringbee/persistence"
+	"maps"
+	"slices"
+	"strings"
+)
+
+var ErrViolation = errors.New("schema violation")
+var ErrOutOfRange = errors.New("value out of range")
+
+type Kind uint8
+
+const (
+	Missing        Kind = 0
+	WrongType      Kind = 1
+	Invalid        Kind = 2
+	Unexpected     Kind = 3
+	MissingSection Kind = 4
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Missing:
+		return "missing"
+	case WrongType:
+		return "wrong type"
+	case Invalid:
+		return "invalid"
+	case Unexpected:
+		return "unexpected"
+	case MissingSection:
+		return "missing section"
+	default:
+		return "unknown"
+	}
+}
+
+type Violation struct {
+	Path string
+	Kind Kind
+	Err  error
+}
+
+func (v Violation) Error() string {
+	if v.Err != nil {
+		return fmt.Sprintf("%s %q: %s: %v", v.Kind, v.Path, ErrViolation, v.Err)
+	}
+
+	return fmt.Sprintf("%s %q: %s", v.Kind, v.Path, ErrViolation)
+}
+
+func (v Violation) Unwrap() []error {
+	if v.Err != nil {
+		return []error{ErrViolation, v.Err}
+	}
+
+	return []error{ErrViolation}
+}
+
+type Field struct {
+	Type     persistence.ValueType
+	Required bool
+	Validate func(value any) error
+}
+
+type Schema struct {
+	Keys       map[string]Field
+	Sections   map[string]Schema
+	AnySection *Schema
+	Required   bool
+	Closed     bool
+}
+
+func (sc Schema) Validate(s persistence.Section) []Violation {
+	var violations []Violation
+	sc.validate(&violations, "", s)
+	return violations
+}
+
+func (sc Schema) validate(violations *[]Violation, base string, s persistence.Section) {
+	for _, k := range slices.Sorted(maps.Keys(sc.Keys)) {
+		f := sc.Keys[k]
+
+		v, vt, found := persistence.Get(s, k)
+		if !found {
+			if f.Required {
+				*violations = append(*violations, Violation{Path: base + k, Kind: Missing})
+			}
+
+			continue
+		}
+
+		if kind, err := f.check(v, vt); err != nil {
+			*violations = append(*violations, Violation{Path: base + k, Kind: kind, Err: err})
+		}
+	}
+
+	if sc.Closed {
+		for _, k := range s.Keys() {
+			if _, declared := sc.Keys[k]; !declared {
+				*violations = append(*violations, Violation{Path: base + k, Kind: Unexpected})
+			}
+		}
+	}
+
+	for _, k := range slices.Sorted(maps.Keys(sc.Sections)) {
+		if sub, ok := s.SectionIfExists(k); ok {
+			sc.Sections[k].validate(violations, base+k+persistence.PathSeparator, sub)
+		} else if sc.Sections[k].Required {
+			*violations = append(*violations, Violation{Path: base + k, Kind: MissingSection})
+		}
+	}
+
+	for k, sub := range s.AllSections() {
+		if _, declared := sc.Sections[k]; declared {
+			continue
+		}
+
+		if sc.AnySection != nil {
+			sc.AnySection.validate(violations, base+k+persistence.PathSeparator, sub)
+		} else if sc.Closed {
+			*violations = append(*violations, Violation{Path: base + k, Kind: Unexpected})
+		}
+	}
+}
+
+func (sc Schema) section(key string) (Schema, bool) {
+	if sub, ok := sc.Sections[key]; ok {
+		return sub, true
+	}
+
+	if sc.AnySection != nil {
+		return *sc.AnySection, true
+	}
+
+	return Schema{}, !sc.Closed
+}
+
+func (f Field) check(v any, vt persistence.ValueType) (Kind, error) {
+	if vt != f.Type {
+		return WrongType, fmt.Errorf("expected type %d, found %d", f.Type, vt)
+	}
+
+	if f.Validate != nil {
+		if err := f.Validate(v); err != nil {
+			return Invalid, err
+		}
+	}
+
+	return 0, nil
+}
+
+func IntRange(min int64, max int64) func(any) error {
+	return func(value any) error {
+		if v, ok := value.(int64); ok && (v < min || v > max) {
+			return fmt.Errorf("%w: %d not within %d to %d", ErrOutOfRange, v, min, max)
+		}
+
+		return nil
+	}
+}
+
+func UIntRange(min uint64, max uint64) func(any) error {
+	return func(value any) error {
+		if v, ok := value.(uint64); ok && (v < min || v > max) {
+			return fmt.Errorf("%w: %d not within %d to %d", ErrOutOfRange, v, min, max)
+		}
+
+		return nil
+	}
+}
+
+func FloatRange(min float64, max float64) func(any) error {
+	return func(value any) error {
+		if v, ok := value.(float64); ok && !(v >= min && v <= max) {
+			return fmt.Errorf("%w: %g not within %g to %g", ErrOutOfRange, v, min, max)
+		}
+
+		return nil
+	}
+}
+
+func LengthRange(min int, max int) func(any) error {
+	return func(value any) error {
+		var l int
+
+		switch v := value.(type) {
+		case string:
+			l = len(v)
+		case []byte:
+			l = len(v)
+		default:
+			return nil
+		}
+
+		if l < min || l > max {
+			return fmt.Errorf("%w: length %d not within %d to %d", ErrOutOfRange, l, min, max)
+		}
+
+		return nil
+	}
+}
+
+func OneOf[T comparable](values ...T) func(any) error {
+	return func(value any) error {
+		if v, ok := value.(T); ok && !slices.Contains(values, v) {
+			strs := make([]string, len(values))
+			for i, a := range values {
+				strs[i] = fmt.Sprint(a)
+			}
+
+			return fmt.Errorf("%w: %v not one of %s", ErrOutOfRange, v, strings.Join(strs, ", "))
+		}
+
+		return nil
+	}
+}
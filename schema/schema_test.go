@@ -0,0 +1,182 @@
+package schema
+
+import (
+	"errors"
+	"github.com/shimmeringbee/persistence"
+	"github.com/shimmeringbee/persistence/impl/memory"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var device = Schema{
+	Keys: map[string]Field{
+		"name":    {Type: persistence.String, Required: true, Validate: LengthRange(1, 32)},
+		"channel": {Type: persistence.Int, Validate: IntRange(11, 26)},
+		"mode":    {Type: persistence.String, Validate: OneOf("router", "end")},
+	},
+	Sections: map[string]Schema{
+		"network": {
+			Required: true,
+			Closed:   true,
+			Keys: map[string]Field{
+				"panId": {Type: persistence.Int, Required: true, Validate: IntRange(0, 0xffff)},
+			},
+		},
+	},
+	AnySection: &Schema{
+		Keys: map[string]Field{
+			"endpoint": {Type: persistence.UnsignedInt, Required: true, Validate: UIntRange(1, 240)},
+		},
+	},
+}
+
+func valid() persistence.Section {
+	s := memory.New()
+	s.Set("name", "bulb")
+	s.Set("channel", 15)
+	s.Section("network").Set("panId", 0x1234)
+	s.Section("ep1").Set("endpoint", uint8(1))
+	return s
+}
+
+func recovered(fn func()) (err error) {
+	defer func() {
+		err, _ = recover().(error)
+	}()
+
+	fn()
+	return nil
+}
+
+func TestSchema_Validate(t *testing.T) {
+	t.Run("valid tree has no violations", func(t *testing.T) {
+		assert.Empty(t, device.Validate(valid()))
+	})
+
+	t.Run("violations are reported with their paths", func(t *testing.T) {
+		s := valid()
+		s.Delete("name")
+		s.Set("channel", "15")
+		s.Set("mode", "coordinator")
+		s.Section("network").Set("extra", true)
+		s.Section("network").Set("panId", 0x10000)
+		s.Section("ep2").Set("endpoint", uint64(241))
+
+		violations := device.Validate(s)
+
+		assert.Equal(t, []string{"channel", "mode", "name", "network/panId", "network/extra", "ep2/endpoint"}, paths(violations))
+		assert.Equal(t, []Kind{WrongType, Invalid, Missing, Invalid, Unexpected, Invalid}, kinds(violations))
+
+		for _, v := range violations {
+			assert.ErrorIs(t, v, ErrViolation)
+		}
+
+		assert.ErrorIs(t, violations[1], ErrOutOfRange)
+	})
+
+	t.Run("missing required sections are reported", func(t *testing.T) {
+		s := valid()
+		s.SectionDelete("network")
+
+		violations := device.Validate(s)
+
+		assert.Equal(t, []Violation{{Path: "network", Kind: MissingSection}}, violations)
+	})
+
+	t.Run("closed schemas report unexpected sections", func(t *testing.T) {
+		s := valid()
+		s.Section("network", "nested")
+
+		violations := device.Validate(s)
+
+		assert.Equal(t, []Violation{{Path: "network/nested", Kind: Unexpected}}, violations)
+	})
+}
+
+func TestEnforce(t *testing.T) {
+	t.Run("valid changes are written through", func(t *testing.T) {
+		s := valid()
+		e := Enforce(s, device)
+
+		e.Set("channel", int8(20))
+		e.Section("network").Set("panId", 0x4321)
+		e.Section("ep2").Set("endpoint", uint16(2))
+		e.Set("undeclared", true)
+
+		v, _ := s.Int("channel")
+		assert.Equal(t, int64(20), v)
+
+		u, _ := s.Section("ep2").UInt("endpoint")
+		assert.Equal(t, uint64(2), u)
+
+		assert.Empty(t, device.Validate(s))
+	})
+
+	t.Run("invalid changes panic without being written", func(t *testing.T) {
+		for _, tc := range []struct {
+			name string
+			fn   func(e persistence.Section)
+			path string
+			kind Kind
+		}{
+			{name: "wrong type", fn: func(e persistence.Section) { e.Set("channel", "20") }, path: "channel", kind: WrongType},
+			{name: "out of range", fn: func(e persistence.Section) { e.Set("channel", 27) }, path: "channel", kind: Invalid},
+			{name: "nested out of range", fn: func(e persistence.Section) { e.Section("ep1").Set("endpoint", uint(0)) }, path: "ep1/endpoint", kind: Invalid},
+			{name: "unexpected key", fn: func(e persistence.Section) { e.Section("network").Set("extra", 1) }, path: "network/extra", kind: Unexpected},
+			{name: "unexpected section", fn: func(e persistence.Section) { e.Section("network", "nested") }, path: "network/nested", kind: Unexpected},
+			{name: "required key deleted", fn: func(e persistence.Section) { e.Delete("name") }, path: "name", kind: Missing},
+			{name: "required section deleted", fn: func(e persistence.Section) { e.SectionDelete("network") }, path: "network", kind: MissingSection},
+			{name: "invalid section renamed", fn: func(e persistence.Section) { e.SectionRename("ep1", "network") }, path: "network/panId", kind: Missing},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				s := valid()
+
+				err := recovered(func() { tc.fn(Enforce(s, device)) })
+
+				var v Violation
+				assert.True(t, errors.As(err, &v))
+				assert.ErrorIs(t, err, ErrViolation)
+				assert.Equal(t, tc.path, v.Path)
+				assert.Equal(t, tc.kind, v.Kind)
+
+				assert.Empty(t, device.Validate(s))
+			})
+		}
+	})
+
+	t.Run("sections are checked against the destination schema when moved", func(t *testing.T) {
+		s := valid()
+		s.Section("spare").Set("panId", 0x1111)
+		s.Section("bare").Set("other", 1)
+		s.SectionDelete("network")
+
+		dst := memory.New()
+		e := Enforce(dst, device)
+
+		assert.True(t, Enforce(s, Schema{}).SectionMove("spare", e, "network"))
+
+		v, _ := dst.Section("network").Int("panId")
+		assert.Equal(t, int64(0x1111), v)
+
+		err := recovered(func() { Enforce(s, Schema{}).SectionMove("bare", e, "ep3") })
+		assert.ErrorIs(t, err, ErrViolation)
+		assert.True(t, s.SectionExists("bare"))
+		assert.False(t, dst.SectionExists("ep3"))
+	})
+}
+
+func paths(violations []Violation) []string {
+	var p []string
+	for _, v := range violations {
+		p = append(p, v.Path)
+	}
+	return p
+}
+
+func kinds(violations []Violation) []Kind {
+	var k []Kind
+	for _, v := range violations {
+		k = append(k, v.Kind)
+	}
+	return k
+}